@@ -0,0 +1,276 @@
+// Package main, the reverse command reverses text read from standard input or files
+/*
+Usage:
+
+	reverse [-mode bytes|runes|graphemes|words|lines] [-pipeline spec] [-invalid fail|replace|keep] [-i]
+		[-log-level debug|info|warn|error] [-log-format text|json] [file ...]
+
+With no files, standard input is reversed to standard output. With -i every
+file is rewritten in place, the new content is written to a temporary file
+next to it which is then renamed over the original so a failure never leaves
+a half written file behind. -pipeline runs the text through steps such as
+"nfc|lower|reverse" instead of -mode, see utilities.ParsePipeline for the steps.
+Errors are logged to standard error, see the logging package for the flags.
+
+Exit codes:
+
+	0 everything was reversed
+	1 a file could not be read or written
+	2 the command line was wrong
+	3 the input was not valid UTF-8 and -invalid=fail was given
+*/
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities"
+)
+
+// The exit codes of the command
+const (
+	exitOK       = 0
+	exitIO       = 1
+	exitUsage    = 2
+	exitEncoding = 3
+)
+
+// Every mode turns the whole input into its reversed form
+var modes = map[string]func(string) string{
+	"bytes": func(s string) string {
+		b := []byte(s)
+		slices.Reverse(b)
+		return string(b)
+	},
+	"runes": func(s string) string {
+		// Invalid bytes were already dealt with by the -invalid policy
+		reversed, _ := utilities.ReverseWithOptions(s, utilities.ReverseOptions{Invalid: utilities.KeepInvalid})
+		return reversed
+	},
+	"graphemes": utilities.ReverseGraphemes,
+	"words":     utilities.ReverseWords,
+	"lines":     utilities.ReverseLines,
+}
+
+var policies = map[string]utilities.InvalidUTF8Policy{
+	"fail":    utilities.FailOnInvalid,
+	"replace": utilities.ReplaceInvalid,
+	"keep":    utilities.KeepInvalid,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Runs the command with the given arguments and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("reverse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	modeName := flags.String("mode", "runes", "what to reverse: bytes, runes, graphemes, words or lines")
+	invalidName := flags.String("invalid", "fail", "what to do with invalid UTF-8: fail, replace or keep")
+	pipeline := flags.String("pipeline", "", "run the text through a pipeline such as nfc|lower|reverse instead of -mode")
+	inPlace := flags.Bool("i", false, "rewrite the files in place instead of writing to standard output")
+	logOpts := logging.DefaultOptions
+	logOpts.AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	// The reversed text goes to stdout, what went wrong is logged to stderr
+	logger := logging.NewWithOptions(stderr, logOpts)
+
+	mode, ok := modes[*modeName]
+	if !ok {
+		logger.Error("unknown mode", "mode", *modeName)
+		return exitUsage
+	}
+	if *pipeline != "" {
+		t, err := utilities.ParsePipeline(*pipeline)
+		if err != nil {
+			logger.Error("bad pipeline", "pipeline", *pipeline, "err", err)
+			return exitUsage
+		}
+		mode = func(s string) string {
+			// The steps are built from strings functions, so there is no error
+			out, _ := utilities.TransformString(t, s)
+			return out
+		}
+	}
+	policy, ok := policies[*invalidName]
+	if !ok {
+		logger.Error("unknown -invalid value", "invalid", *invalidName)
+		return exitUsage
+	}
+	// Bytes are reversed as they are, there is nothing to decode
+	if *modeName == "bytes" && *pipeline == "" {
+		policy = utilities.KeepInvalid
+	}
+	// Runes with replaced invalid bytes is what a ReverseReader hands out, so
+	// files can be reversed without reading all of them into memory
+	r := reverser{logger: logger, mode: mode, policy: policy, seekable: *modeName == "runes" && *pipeline == "" && policy == utilities.ReplaceInvalid}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		if *inPlace {
+			logger.Error("-i needs at least one file")
+			return exitUsage
+		}
+		return r.report("standard input", r.stream(stdin, stdout))
+	}
+
+	code := exitOK
+	for _, name := range files {
+		var err error
+		if *inPlace {
+			err = r.rewrite(name)
+		} else {
+			err = r.file(name, stdout)
+		}
+		// An I/O error is the more serious one, so it wins over an encoding error
+		if c := r.report(name, err); c != exitOK && (code == exitOK || c == exitIO) {
+			code = c
+		}
+	}
+	return code
+}
+
+// Holds the choices made on the command line
+type reverser struct {
+	logger   *slog.Logger
+	mode     func(string) string
+	policy   utilities.InvalidUTF8Policy
+	seekable bool
+}
+
+// Logs err, if there is one, and returns the matching exit code
+func (r reverser) report(name string, err error) int {
+	if err == nil {
+		r.logger.Debug("reversed", "file", name)
+		return exitOK
+	}
+	var invalid *utilities.InvalidUTF8Error
+	if errors.As(err, &invalid) {
+		r.logger.Error("invalid UTF-8", "file", name, "offset", invalid.Offset)
+		return exitEncoding
+	}
+	r.logger.Error("cannot reverse", "file", name, "err", err)
+	return exitIO
+}
+
+// Reverses everything read from in and writes it to out
+func (r reverser) stream(in io.Reader, out io.Writer) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	reversed, err := r.reverse(data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, reversed)
+	return err
+}
+
+// Reverses the file called name to out
+func (r reverser) file(name string, out io.Writer) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if r.seekable {
+		if rr, err := utilities.OpenReverseReader(f); err == nil {
+			_, err = io.Copy(out, rr)
+			return err
+		}
+		// Pipes and other files that cannot seek are read whole instead
+	}
+	return r.stream(f, out)
+}
+
+// Replaces the content of the file called name with its reversed form
+func (r reverser) rewrite(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	reversed, err := r.reverse(data)
+	if err != nil {
+		return err
+	}
+
+	// The temporary file lives in the same directory so that the rename
+	// stays on one file system and is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".reverse-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.WriteString(tmp, reversed); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Applies the invalid UTF-8 policy to data and then the mode
+func (r reverser) reverse(data []byte) (string, error) {
+	switch r.policy {
+	case utilities.FailOnInvalid:
+		if !utf8.Valid(data) {
+			return "", &utilities.InvalidUTF8Error{Offset: firstInvalid(data)}
+		}
+	case utilities.ReplaceInvalid:
+		data = replaceInvalid(data)
+	}
+	return r.mode(string(data)), nil
+}
+
+// Returns the byte offset of the first invalid UTF-8 sequence in data
+func firstInvalid(data []byte) int {
+	for i := 0; i < len(data); {
+		r, n := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && n == 1 {
+			return i
+		}
+		i += n
+	}
+	return -1
+}
+
+// Turns every invalid byte into U+FFFD, the same way Reverse does it
+func replaceInvalid(data []byte) []byte {
+	if utf8.Valid(data) {
+		return data
+	}
+	var b bytes.Buffer
+	for i := 0; i < len(data); {
+		r, n := utf8.DecodeRune(data[i:])
+		b.WriteRune(r)
+		i += n
+	}
+	return b.Bytes()
+}
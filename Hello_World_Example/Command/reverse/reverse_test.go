@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		args       []string
+		in         string
+		wantOut    string
+		wantStatus int
+	}{
+		{nil, "Hello, 世界", "界世 ,olleH", exitOK},
+		{[]string{"-mode", "bytes"}, "ab\xff", "\xffba", exitOK},
+		{[]string{"-mode", "graphemes"}, "café", "éfac", exitOK},
+		{[]string{"-mode", "words"}, "Hello, world!\n", "world! Hello,\n", exitOK},
+		{[]string{"-mode", "lines"}, "a\nb\n", "b\na\n", exitOK},
+		{nil, "a\xffb", "", exitEncoding},
+		{[]string{"-invalid", "replace"}, "a\xffb", "b�a", exitOK},
+		{[]string{"-invalid", "keep"}, "a\xffb", "b\xffa", exitOK},
+		{[]string{"-pipeline", "nfc|upper|reverse"}, "Cafe\u0301", "ÉFAC", exitOK},
+		{[]string{"-pipeline", "sideways"}, "abc", "", exitUsage},
+		{[]string{"-mode", "sideways"}, "abc", "", exitUsage},
+		{[]string{"-invalid", "maybe"}, "abc", "", exitUsage},
+		{[]string{"-no-such-flag"}, "abc", "", exitUsage},
+		{[]string{"-i"}, "abc", "", exitUsage},
+		{[]string{"does-not-exist.txt"}, "", "", exitIO},
+	}
+
+	for _, c := range cases {
+		var stdout, stderr bytes.Buffer
+		status := run(c.args, strings.NewReader(c.in), &stdout, &stderr)
+		if status != c.wantStatus || stdout.String() != c.wantOut {
+			t.Errorf("run(%q) with %q == %d, %q, want %d, %q (stderr %q)",
+				c.args, c.in, status, stdout.String(), c.wantStatus, c.wantOut, stderr.String())
+		}
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	os.WriteFile(first, []byte("abc"), 0o644)
+	os.WriteFile(second, []byte("Hello, 世界"), 0o644)
+
+	// Both the streaming and the whole file path must give the same result
+	for _, invalid := range []string{"fail", "replace"} {
+		var stdout, stderr bytes.Buffer
+		status := run([]string{"-invalid", invalid, first, second}, nil, &stdout, &stderr)
+		if status != exitOK || stdout.String() != "cba界世 ,olleH" {
+			t.Errorf("-invalid %s: run == %d, %q (stderr %q)", invalid, status, stdout.String(), stderr.String())
+		}
+	}
+
+	// A missing file is an I/O error even when another file is badly encoded
+	bad := filepath.Join(dir, "bad.txt")
+	os.WriteFile(bad, []byte("\xff"), 0o644)
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{bad, filepath.Join(dir, "missing.txt")}, nil, &stdout, &stderr); status != exitIO {
+		t.Errorf("run == %d, want %d (stderr %q)", status, exitIO, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), `msg="invalid UTF-8"`) || !strings.Contains(stderr.String(), "offset=0") {
+		t.Errorf("stderr %q does not mention the encoding error", stderr.String())
+	}
+}
+
+func TestRunInPlace(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "lines.txt")
+	os.WriteFile(name, []byte("one\ntwo\nthree\n"), 0o600)
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-i", "-mode", "lines", name}, nil, &stdout, &stderr); status != exitOK {
+		t.Fatalf("run == %d (stderr %q)", status, stderr.String())
+	}
+	got, _ := os.ReadFile(name)
+	if string(got) != "three\ntwo\none\n" {
+		t.Errorf("file holds %q", got)
+	}
+	if info, _ := os.Stat(name); info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode == %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout == %q, want nothing", stdout.String())
+	}
+
+	// A file that cannot be reversed is left alone and no temporary file stays behind
+	bad := filepath.Join(dir, "bad.txt")
+	os.WriteFile(bad, []byte("a\xff"), 0o644)
+	if status := run([]string{"-i", bad}, nil, &stdout, &stderr); status != exitEncoding {
+		t.Errorf("run == %d, want %d", status, exitEncoding)
+	}
+	if got, _ := os.ReadFile(bad); string(got) != "a\xff" {
+		t.Errorf("bad file holds %q", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("directory holds %d entries, want 2", len(entries))
+	}
+}
@@ -0,0 +1,76 @@
+// Package main, the utilrpc command serves the string functions of the utilities
+// package over JSON-RPC
+/*
+Usage:
+
+	utilrpc [-listen address] [-log-level debug|info|warn|error] [-log-format text|json]
+
+The address is a TCP address such as localhost:7070, the default, or "unix:"
+and the path of a socket such as unix:/run/utilrpc.sock. The server runs until
+it gets SIGTERM or an interrupt, then closes its connections and exits. See the
+utilrpc package for the calls and its Client for calling them from Go. What the
+server does is logged to standard error.
+
+Exit codes:
+
+	0 the server was shut down
+	1 the address could not be listened on or serving failed
+	2 the command line was wrong
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilrpc"
+)
+
+// The exit codes of the command
+const (
+	exitOK    = 0
+	exitIO    = 1
+	exitUsage = 2
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stderr))
+}
+
+// Runs the command with the given arguments until ctx is done and returns the
+// exit code
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("utilrpc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	address := flags.String("listen", "localhost:7070", "the TCP address, or unix: and the path of a socket, to listen on")
+	logOpts := logging.DefaultOptions
+	logOpts.AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	logger := logging.NewWithOptions(stderr, logOpts)
+	if flags.NArg() > 0 {
+		logger.Error("unexpected argument", "arg", flags.Arg(0))
+		return exitUsage
+	}
+
+	ln, err := utilrpc.Listen(*address)
+	if err != nil {
+		logger.Error("cannot listen", "address", *address, "err", err)
+		return exitIO
+	}
+	logger.Info("serving", "network", ln.Addr().Network(), "address", ln.Addr().String())
+	if err := utilrpc.Serve(ctx, ln); err != nil {
+		logger.Error("serving failed", "err", err)
+		return exitIO
+	}
+	logger.Info("shut down")
+	return exitOK
+}
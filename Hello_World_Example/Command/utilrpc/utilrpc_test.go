@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilrpc"
+)
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		args       []string
+		wantStatus int
+	}{
+		{[]string{"-listen", "unix:" + filepath.Join(t.TempDir(), "no", "such", "dir.sock")}, exitIO},
+		{[]string{"-listen", "256.0.0.1:1"}, exitIO},
+		{[]string{"extra"}, exitUsage},
+		{[]string{"-no-such-flag"}, exitUsage},
+	}
+
+	for _, c := range cases {
+		var stderr bytes.Buffer
+		if status := run(context.Background(), c.args, &stderr); status != c.wantStatus {
+			t.Errorf("run(%q) == %d, want %d (stderr %q)", c.args, status, c.wantStatus, stderr.String())
+		}
+	}
+}
+
+func TestRunServes(t *testing.T) {
+	socket := "unix:" + filepath.Join(t.TempDir(), "utilrpc.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	status := make(chan int, 1)
+	go func() {
+		var stderr bytes.Buffer
+		status <- run(ctx, []string{"-listen", socket}, &stderr)
+	}()
+
+	// Wait for the socket to be there
+	var client *utilrpc.Client
+	for deadline := time.Now().Add(5 * time.Second); client == nil; time.Sleep(10 * time.Millisecond) {
+		var err error
+		if client, err = utilrpc.Dial(ctx, socket); err != nil && time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+	}
+	defer client.Close()
+	if got, err := client.Reverse(ctx, "Hello, 世界"); err != nil || got != "界世 ,olleH" {
+		t.Errorf("Reverse == %q, %v", got, err)
+	}
+
+	cancel()
+	if got := <-status; got != exitOK {
+		t.Errorf("run == %d after shutdown, want %d", got, exitOK)
+	}
+}
@@ -1,20 +1,139 @@
 // Package main, executable comman should always use the package name "main"
 package main
 
-// Import a standard library for print formatting
+// Import the standard libraries for flags and print formatting
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/config"
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
 	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities"
 )
 
 // Define the entry point of the application
 func main() {
-	// Simply prints a line to the console using the fmt package
-	fmt.Println("Hello, world.")
-	// Print the Hello, world. backwards
-	backwardsString := utilities.Reverse("Hello, world.")
-	fmt.Println(backwardsString)
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stdout, os.Stderr))
+}
+
+// The settings of the command. Each can be given in the config file, as an
+// environment variable such as HELLO_NAME or as a flag such as -name, see the
+// config package for which one wins
+var configKeys = []config.Key{
+	{Name: "lang", Usage: "the locale to greet in, such as pt_BR, instead of the one in LANG"},
+	{Name: "name", Usage: "who to greet instead of the world"},
+	{Name: "greeting", Usage: "the greeting to use instead of the one of the locale, {name} is replaced by the name or the world"},
+	{Name: "output", Default: "-", Usage: "the file to write the greeting to, - is standard output"},
+	{Name: "serve", Usage: "serve /hello and /reverse over HTTP on an address such as :8080"},
+}
+
+// Runs the command with the given arguments and environment and returns the
+// exit code. The greeting is in the language of the lang setting, or of LC_ALL,
+// LC_MESSAGES or LANG when it is not given. With serve it runs as an HTTP
+// service until it gets SIGTERM or an interrupt, see newHandler
+func run(args []string, getenv func(string) string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("Hello_World", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	loader := config.NewLoader("HELLO_", configKeys...)
+	loader.AddFlags(flags)
+	configFile := flags.String("config", "", "read settings from a .json or .toml file, HELLO_CONFIG also names one")
+	showConfig := flags.Bool("show-config", false, "print every setting and where it came from, then exit")
+	logOpts := logging.DefaultOptions
+	logOpts.AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	// Messages about the program go to stderr, the greeting itself to stdout
+	logger := logging.NewWithOptions(stderr, logOpts)
+	if catalogsErr != nil {
+		logger.Error("cannot read the message catalogs", "err", catalogsErr)
+		return 1
+	}
+
+	if *configFile == "" {
+		*configFile = getenv("HELLO_CONFIG")
+	}
+	cfg, err := loader.Load(*configFile, getenv)
+	if err != nil {
+		logger.Error("cannot read the config", "err", err)
+		return 2
+	}
+	if *showConfig {
+		for _, setting := range cfg.Settings() {
+			fmt.Fprintln(stdout, setting)
+		}
+		return 0
+	}
+
+	lang := cfg.Get("lang")
+	if lang == "" {
+		lang = localeFromEnv(getenv)
+	}
+	logger.Debug("picked a locale", "locale", lang)
+	if addr := cfg.Get("serve"); addr != "" {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			logger.Error("cannot listen", "addr", addr, "err", err)
+			return 1
+		}
+		logger.Info("serving", "addr", ln.Addr().String())
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		if err := serve(ctx, ln, newHandler(lang, logger), logger); err != nil {
+			logger.Error("serving failed", "err", err)
+			return 1
+		}
+		return 0
+	}
+	l := newLocalizer(lang)
+
+	out := stdout
+	var file *os.File
+	switch output, _ := cfg.Lookup("output"); output.Value {
+	case "-":
+	case "":
+		// os.Create("") would fail with an error that does not say where the
+		// empty name came from
+		logger.Error("the output is empty, want a file name or - for standard output", "setting", output.String())
+		return 2
+	default:
+		if file, err = os.Create(output.Value); err != nil {
+			logger.Error("cannot create the output", "err", err)
+			return 1
+		}
+		out = file
+	}
+
+	greeting := l.Text("hello", nil)
+	// Without a name a custom greeting greets the world of the locale
+	vars := map[string]string{"name": l.Text("world", nil)}
+	if name := cfg.Get("name"); name != "" {
+		vars["name"] = name
+		greeting = l.Text("helloName", vars)
+	}
+	if custom := cfg.Get("greeting"); custom != "" {
+		greeting = fill(custom, vars)
+	}
+	// Simply prints the lines using the fmt package, the greeting and the
+	// greeting backwards
+	_, err = fmt.Fprintf(out, "%s\n%s\n", greeting, utilities.Reverse(greeting))
+	if file != nil {
+		// A full disk may only show once the file is closed
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		logger.Error("cannot write the greeting", "err", err)
+		return 1
+	}
+	return 0
 }
 
 // Build and run the application using the go tool and the following command
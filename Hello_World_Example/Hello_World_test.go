@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunConfig(t *testing.T) {
+	dir := t.TempDir()
+	tomlFile := writeFile(t, "hello.toml", "# Greets Ada in German\nlang = \"de\"\nname = \"Ada\"\n")
+	jsonFile := writeFile(t, "hello.json", "{\n  \"greeting\": \"Ahoy, {name}!\"\n}\n")
+	typo := writeFile(t, "typo.toml", "name = \"Ada\"\nlanguage = \"de\"\n")
+	emptyOutput := writeFile(t, "empty.toml", "output = \"\"\n")
+	output := filepath.Join(dir, "out.txt")
+
+	cases := []struct {
+		args       []string
+		env        map[string]string
+		wantOut    string
+		wantStatus int
+	}{
+		{[]string{"-config", tomlFile}, nil, "Hallo, Ada.\n.adA ,ollaH\n", 0},
+		// The environment wins over the file and a flag over both
+		{[]string{"-config", tomlFile}, map[string]string{"HELLO_NAME": "Bob"}, "Hallo, Bob.\n.boB ,ollaH\n", 0},
+		{[]string{"-config", tomlFile, "-name", "Cy"}, map[string]string{"HELLO_NAME": "Bob"}, "Hallo, Cy.\n.yC ,ollaH\n", 0},
+		{nil, map[string]string{"HELLO_CONFIG": jsonFile, "HELLO_NAME": "Bob"}, "Ahoy, Bob!\n!boB ,yohA\n", 0},
+		// Without a name {name} is the world in the language of the locale
+		{[]string{"-config", jsonFile, "-lang", "de"}, nil, "Ahoy, Welt!\n!tleW ,yohA\n", 0},
+		{[]string{"-config", jsonFile, "-show-config"}, map[string]string{"HELLO_LANG": "fr"}, strings.Join([]string{
+			`lang="fr" (env HELLO_LANG)`,
+			`name="" (default)`,
+			`greeting="Ahoy, {name}!" (file ` + jsonFile + `:2)`,
+			`output="-" (default)`,
+			`serve="" (default)`,
+		}, "\n") + "\n", 0},
+		{[]string{"-config", typo}, nil, "", 2},
+		{[]string{"-config", filepath.Join(dir, "missing.toml")}, nil, "", 2},
+		{[]string{"-output", output}, nil, "", 0},
+		{[]string{"-output", filepath.Join(dir, "no", "such", "dir")}, nil, "", 1},
+		{[]string{"-output", ""}, nil, "", 2},
+		{[]string{"-config", emptyOutput}, nil, "", 2},
+	}
+
+	for _, c := range cases {
+		var stdout, stderr bytes.Buffer
+		status := run(c.args, func(name string) string { return c.env[name] }, &stdout, &stderr)
+		if status != c.wantStatus || stdout.String() != c.wantOut {
+			t.Errorf("run(%q) with %v == %d, %q, want %d, %q (stderr %q)",
+				c.args, c.env, status, stdout.String(), c.wantStatus, c.wantOut, stderr.String())
+		}
+	}
+
+	if got, _ := os.ReadFile(output); string(got) != "Hello, world.\n.dlrow ,olleH\n" {
+		t.Errorf("-output wrote %q", got)
+	}
+	var stderr bytes.Buffer
+	run([]string{"-config", typo}, func(string) string { return "" }, nil, &stderr)
+	if !strings.Contains(stderr.String(), typo+`:2: unknown key \"language\"`) {
+		t.Errorf("stderr %q does not point at the unknown key", stderr.String())
+	}
+	stderr.Reset()
+	run([]string{"-config", emptyOutput}, func(string) string { return "" }, nil, &stderr)
+	if !strings.Contains(stderr.String(), emptyOutput+":1") {
+		t.Errorf("stderr %q does not say where the empty output came from", stderr.String())
+	}
+}
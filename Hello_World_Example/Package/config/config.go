@@ -0,0 +1,340 @@
+// Package config gathers the settings of a program from several places. Every
+// setting has a default, which a config file overrides, which an environment
+// variable overrides, which a flag overrides. The config file is JSON or the
+// subset of TOML that ParseTOML reads, picked by its extension, and a key in it
+// that the program does not know is an error that gives its line
+/*
+loader := config.NewLoader("HELLO_",
+	config.Key{Name: "name", Usage: "who to greet"},
+	config.Key{Name: "output", Default: "-", Usage: "where to write"},
+)
+loader.AddFlags(flags)
+flags.Parse(args)
+cfg, err := loader.Load("hello.toml", os.Getenv)
+cfg.Get("name")
+cfg.Lookup("name") // {name Ada file hello.toml:2}
+*/
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source tells where the value of a setting came from
+type Source int
+
+const (
+	// SourceDefault is the default the program gave the key
+	SourceDefault Source = iota
+	// SourceFile is the config file
+	SourceFile
+	// SourceEnv is an environment variable
+	SourceEnv
+	// SourceFlag is a flag on the command line
+	SourceFlag
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// Key is a setting the program knows about. Its name is also the name of its
+// flag, and of its environment variable in upper case with the prefix and with
+// dots and hyphens turned into underscores, so "log.level" is -log.level and
+// HELLO_LOG_LEVEL
+type Key struct {
+	Name    string
+	Default string
+	Usage   string
+}
+
+// Setting is the value a key ended up with and where it came from. Origin
+// says where exactly, such as "hello.toml:3", "HELLO_NAME" or "-name"
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+	Origin string
+}
+
+func (s Setting) String() string {
+	if s.Origin == "" {
+		return fmt.Sprintf("%s=%q (%v)", s.Key, s.Value, s.Source)
+	}
+	return fmt.Sprintf("%s=%q (%v %s)", s.Key, s.Value, s.Source, s.Origin)
+}
+
+// Error is a mistake in a config file, Line is 0 when it is not about a line
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Entry is one key and value read from a config file and the line it is on.
+// Tables and nested objects are flattened into dotted keys
+type Entry struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// Loader knows the keys of a program and gathers their values
+type Loader struct {
+	keys      []Key
+	envPrefix string
+	// The flags that were added with AddFlags, by key
+	flags map[string]*flagValue
+}
+
+// NewLoader returns a Loader for keys whose environment variables start with
+// envPrefix
+func NewLoader(envPrefix string, keys ...Key) *Loader {
+	return &Loader{keys: keys, envPrefix: envPrefix, flags: map[string]*flagValue{}}
+}
+
+// Records whether a flag was given, so that a flag given with the default
+// value still wins over the file and the environment
+type flagValue struct {
+	value string
+	set   bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *flagValue) Set(s string) error {
+	f.value, f.set = s, true
+	return nil
+}
+
+// AddFlags adds a flag for every key to flags
+func (l *Loader) AddFlags(flags *flag.FlagSet) {
+	for _, key := range l.keys {
+		value := &flagValue{value: key.Default}
+		l.flags[key.Name] = value
+		flags.Var(value, key.Name, key.Usage)
+	}
+}
+
+// EnvName returns the environment variable of key
+func (l *Loader) EnvName(key string) string {
+	return l.envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Load gathers the value of every key from the defaults, the config file
+// called file unless it is "", the environment that getenv reads and the
+// flags added with AddFlags, later ones overriding earlier ones. An empty
+// environment variable counts as not set
+func (l *Loader) Load(file string, getenv func(string) string) (*Config, error) {
+	c := &Config{settings: make(map[string]Setting, len(l.keys))}
+	for _, key := range l.keys {
+		c.order = append(c.order, key.Name)
+		c.settings[key.Name] = Setting{Key: key.Name, Value: key.Default, Source: SourceDefault}
+	}
+
+	if file != "" {
+		entries, err := ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if _, ok := c.settings[entry.Key]; !ok {
+				return nil, &Error{File: file, Line: entry.Line, Msg: fmt.Sprintf("unknown key %q", entry.Key)}
+			}
+			c.settings[entry.Key] = Setting{
+				Key: entry.Key, Value: entry.Value, Source: SourceFile, Origin: fmt.Sprintf("%s:%d", file, entry.Line),
+			}
+		}
+	}
+
+	for _, key := range l.keys {
+		name := l.EnvName(key.Name)
+		if value := getenv(name); value != "" {
+			c.settings[key.Name] = Setting{Key: key.Name, Value: value, Source: SourceEnv, Origin: name}
+		}
+		if f := l.flags[key.Name]; f != nil && f.set {
+			c.settings[key.Name] = Setting{Key: key.Name, Value: f.value, Source: SourceFlag, Origin: "-" + key.Name}
+		}
+	}
+	return c, nil
+}
+
+// ReadFile reads the config file called name, as JSON when it ends in .json
+// and as TOML when it ends in .toml
+func ReadFile(name string) ([]Entry, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return ParseJSON(name, data)
+	case ".toml":
+		return ParseTOML(name, data)
+	}
+	return nil, &Error{File: name, Msg: "unknown config format, want a .json or .toml file"}
+}
+
+// ParseJSON reads a JSON object. Strings, numbers and booleans are values,
+// nested objects are flattened into dotted keys. Arrays and null are errors.
+// name is only used in errors
+/*
+{"name": "Ada", "log": {"level": "debug"}}   name=Ada  log.level=debug
+*/
+func ParseJSON(name string, data []byte) ([]Entry, error) {
+	p := jsonParser{name: name, data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+	if err := p.expectDelim('{'); err != nil {
+		return nil, err
+	}
+	if err := p.object(""); err != nil {
+		return nil, err
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		return nil, p.errorf("text after the end of the object")
+	}
+	return p.entries, nil
+}
+
+type jsonParser struct {
+	name    string
+	data    []byte
+	dec     *json.Decoder
+	entries []Entry
+	seen    map[string]bool
+}
+
+// Returns the line the decoder is on
+func (p *jsonParser) line() int {
+	return 1 + bytes.Count(p.data[:p.dec.InputOffset()], []byte("\n"))
+}
+
+func (p *jsonParser) errorf(format string, args ...any) error {
+	return &Error{File: p.name, Line: p.line(), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jsonParser) token() (json.Token, error) {
+	t, err := p.dec.Token()
+	if err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line := 1 + bytes.Count(p.data[:min(int(syntax.Offset), len(p.data))], []byte("\n"))
+			return nil, &Error{File: p.name, Line: line, Msg: syntax.Error()}
+		}
+		return nil, p.errorf("%v", err)
+	}
+	return t, nil
+}
+
+func (p *jsonParser) expectDelim(want json.Delim) error {
+	t, err := p.token()
+	if err != nil {
+		return err
+	}
+	if t != want {
+		return p.errorf("want %v, found %v", want, t)
+	}
+	return nil
+}
+
+// Reads the members of an object whose { was read, prefix is the dotted key
+// of the object
+func (p *jsonParser) object(prefix string) error {
+	for p.dec.More() {
+		t, err := p.token()
+		if err != nil {
+			return err
+		}
+		key := prefix + t.(string)
+		line := p.line()
+		if p.seen == nil {
+			p.seen = map[string]bool{}
+		}
+		if p.seen[key] {
+			return p.errorf("key %q given twice", key)
+		}
+		p.seen[key] = true
+
+		t, err = p.token()
+		if err != nil {
+			return err
+		}
+		switch v := t.(type) {
+		case json.Delim:
+			if v != '{' {
+				return p.errorf("%q: arrays are not supported", key)
+			}
+			if err := p.object(key + "."); err != nil {
+				return err
+			}
+		case string:
+			p.entries = append(p.entries, Entry{Key: key, Value: v, Line: line})
+		case json.Number:
+			p.entries = append(p.entries, Entry{Key: key, Value: v.String(), Line: line})
+		case bool:
+			p.entries = append(p.entries, Entry{Key: key, Value: fmt.Sprint(v), Line: line})
+		default:
+			return p.errorf("%q: null is not a value", key)
+		}
+	}
+	// The closing }
+	_, err := p.token()
+	return err
+}
+
+// Config holds the settings Load gathered
+type Config struct {
+	settings map[string]Setting
+	// The keys in the order the Loader was given them
+	order []string
+}
+
+// Get returns the value of key, "" for a key the Loader does not know
+func (c *Config) Get(key string) string {
+	return c.settings[key].Value
+}
+
+// Lookup returns the setting of key and whether the Loader knows it
+func (c *Config) Lookup(key string) (Setting, bool) {
+	s, ok := c.settings[key]
+	return s, ok
+}
+
+// Settings returns every setting in the order the keys were given to the Loader
+func (c *Config) Settings() []Setting {
+	out := make([]Setting, 0, len(c.order))
+	for _, key := range c.order {
+		out = append(out, c.settings[key])
+	}
+	return out
+}
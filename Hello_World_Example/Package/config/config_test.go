@@ -0,0 +1,124 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newTestLoader() *Loader {
+	return NewLoader("HELLO_",
+		Key{Name: "name", Default: "world", Usage: "who to greet"},
+		Key{Name: "greeting", Usage: "the greeting"},
+		Key{Name: "output", Default: "-", Usage: "where to write"},
+		Key{Name: "log.level", Default: "info", Usage: "what to log"},
+	)
+}
+
+func writeFile(t *testing.T, name, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "hello.toml", "name = \"file\"\ngreeting = \"Hi\"\n\n[log]\nlevel = \"warn\"\n")
+	env := map[string]string{"HELLO_NAME": "env", "HELLO_LOG_LEVEL": "debug", "HELLO_OUTPUT": ""}
+
+	loader := newTestLoader()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader.AddFlags(flags)
+	// A flag given with its default value still wins
+	if err := flags.Parse([]string{"-log.level", "info"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load(file, func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Setting{
+		{"name", "env", SourceEnv, "HELLO_NAME"},
+		{"greeting", "Hi", SourceFile, file + ":2"},
+		{"output", "-", SourceDefault, ""},
+		{"log.level", "info", SourceFlag, "-log.level"},
+	}
+	if got := cfg.Settings(); !slices.Equal(got, want) {
+		t.Errorf("Settings() == %v, want %v", got, want)
+	}
+	if got := cfg.Get("name"); got != "env" {
+		t.Errorf("Get(%q) == %q, want %q", "name", got, "env")
+	}
+	if _, ok := cfg.Lookup("nothing"); ok {
+		t.Errorf("Lookup of an unknown key succeeded")
+	}
+	if got := want[1].String(); got != `greeting="Hi" (file `+file+`:2)` {
+		t.Errorf("Setting.String() == %s", got)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	file := writeFile(t, "hello.json", `{
+	"name": "Ada",
+	"log": {
+		"level": "error"
+	}
+}`)
+	cfg, err := newTestLoader().Load(file, func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := cfg.Lookup("log.level"); s.Value != "error" || s.Origin != file+":4" {
+		t.Errorf("log.level == %v", s)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name, text string
+		want       string
+	}{
+		{"hello.toml", "name = \"Ada\"\n\ngreting = \"Hi\"\n", `:3: unknown key "greting"`},
+		{"hello.toml", "[log]\nlevel = \"debug\"\nformat = \"json\"\n", `:3: unknown key "log.format"`},
+		{"hello.json", "{\n  \"name\": \"Ada\",\n  \"colour\": \"red\"\n}", `:3: unknown key "colour"`},
+		{"hello.json", "{\n  \"log\": {\n    \"lvl\": 1\n  }\n}", `:3: unknown key "log.lvl"`},
+		{"hello.json", "{\n  \"name\": \"Ada\",\n  \"name\": \"Bob\"\n}", `:3: key "name" given twice`},
+		{"hello.json", "{\n  \"name\": [\"Ada\"]\n}", `:2: "name": arrays are not supported`},
+		{"hello.json", "{\n  \"name\": null\n}", `:2: "name": null is not a value`},
+		{"hello.json", "{\n  \"name\": \"Ada\",\n}", `:2: invalid character ',' looking for beginning of value`},
+		{"hello.json", `["name"]`, `:1: want {, found [`},
+		{"hello.json", `{} {}`, `:1: text after the end of the object`},
+		{"hello.yaml", "name: Ada", `: unknown config format, want a .json or .toml file`},
+	}
+
+	for _, c := range cases {
+		file := writeFile(t, c.name, c.text)
+		_, err := newTestLoader().Load(file, func(string) string { return "" })
+		if err == nil || err.Error() != file+c.want {
+			t.Errorf("Loading %q == %v, want %s%s", c.text, err, file, c.want)
+		}
+	}
+
+	if _, err := newTestLoader().Load(filepath.Join(t.TempDir(), "missing.toml"), nil); !os.IsNotExist(err) {
+		t.Errorf("Loading a missing file == %v", err)
+	}
+}
+
+func TestFlagsDefaults(t *testing.T) {
+	loader := newTestLoader()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	loader.AddFlags(flags)
+	if got := flags.Lookup("output").DefValue; got != "-" {
+		t.Errorf("The default of -output is %q, want %q", got, "-")
+	}
+	if got := loader.EnvName("log.level"); got != "HELLO_LOG_LEVEL" {
+		t.Errorf("EnvName(%q) == %q, want %q", "log.level", got, "HELLO_LOG_LEVEL")
+	}
+}
@@ -0,0 +1,379 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseTOML reads the part of TOML that flat settings need. It takes
+//
+//   - comments from # to the end of the line
+//   - tables, [server] and [log.file], whose keys are put under the table name
+//   - bare keys of letters, digits, _ and -, quoted keys and dotted keys
+//   - basic strings with the escapes of TOML, literal strings in single
+//     quotes, integers with _ between digits, also in hexadecimal, octal and
+//     binary as 0x1F, 0o17 and 0b1, which are given in decimal, floats and the
+//     booleans true and false
+//
+// Arrays, inline tables, multi-line strings and dates give an error that says
+// so instead of being read wrong. A key is either a value or a table, a = 1
+// followed by [a] or a.b = 2 is an error. name is only used in errors
+/*
+# Who to greet
+name = "Ada"
+
+[log]
+level = 'debug'   # log.level=debug
+*/
+func ParseTOML(name string, data []byte) ([]Entry, error) {
+	var entries []Entry
+	// The line every value, table given with [name], table that such a name is
+	// in and table made by a dotted key was defined on
+	values, tables := map[string]int{}, map[string]int{}
+	parents, dotted := map[string]int{}, map[string]int{}
+	table := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		p := tomlLine{file: name, number: i + 1, rest: strings.TrimSuffix(line, "\r")}
+		if !utf8.ValidString(p.rest) {
+			return nil, p.errorf("the line is not valid UTF-8")
+		}
+		p.skipSpace()
+		if p.done() {
+			continue
+		}
+
+		if strings.HasPrefix(p.rest, "[") {
+			if strings.HasPrefix(p.rest, "[[") {
+				return nil, p.errorf("arrays of tables are not supported")
+			}
+			p.rest = p.rest[1:]
+			key, err := p.key()
+			if err != nil {
+				return nil, err
+			}
+			if !strings.HasPrefix(p.rest, "]") {
+				return nil, p.errorf("want ] after the table name")
+			}
+			p.rest = p.rest[1:]
+			p.skipSpace()
+			if !p.done() {
+				return nil, p.errorf("unexpected %q after the table name", p.rest)
+			}
+			if at, ok := tables[key]; ok {
+				return nil, p.errorf("table [%s] was already defined on line %d", key, at)
+			}
+			if at, ok := dotted[key]; ok {
+				return nil, p.errorf("table [%s] was already defined by a dotted key on line %d", key, at)
+			}
+			if err := p.checkTable(key+".", values); err != nil {
+				return nil, err
+			}
+			tables[key] = p.number
+			addParents(parents, key, 0, p.number)
+			table = key + "."
+			continue
+		}
+
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(p.rest, "=") {
+			return nil, p.errorf("want = after the key %q", key)
+		}
+		p.rest = p.rest[1:]
+		p.skipSpace()
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.done() {
+			return nil, p.errorf("unexpected %q after the value", p.rest)
+		}
+
+		key = table + key
+		if at, ok := values[key]; ok {
+			return nil, p.errorf("key %q was already given on line %d", key, at)
+		}
+		for _, defined := range []map[string]int{tables, parents, dotted} {
+			if at, ok := defined[key]; ok {
+				return nil, p.errorf("key %q is a table since line %d", key, at)
+			}
+		}
+		if err := p.checkTable(key, values); err != nil {
+			return nil, err
+		}
+		values[key] = p.number
+		addParents(dotted, key, len(table), p.number)
+		entries = append(entries, Entry{Key: key, Value: value, Line: p.number})
+	}
+	return entries, nil
+}
+
+// Returns an error when a table that key is in, such as a and a.b for a.b.c,
+// is a value. A table name is given with a dot at the end, so that it is
+// checked as well
+func (p *tomlLine) checkTable(key string, values map[string]int) error {
+	for i := range len(key) {
+		if key[i] != '.' {
+			continue
+		}
+		if at, ok := values[key[:i]]; ok {
+			return p.errorf("%q is a value since line %d, not a table", key[:i], at)
+		}
+	}
+	return nil
+}
+
+// Records the tables that key is in, from the first dot at from or after, as
+// defined on line unless they already were
+func addParents(defined map[string]int, key string, from int, line int) {
+	for i := from; i < len(key); i++ {
+		if _, ok := defined[key[:i]]; key[i] == '.' && !ok {
+			defined[key[:i]] = line
+		}
+	}
+}
+
+// The part of a line that is still to be read
+type tomlLine struct {
+	file   string
+	number int
+	rest   string
+}
+
+func (p *tomlLine) errorf(format string, args ...any) error {
+	return &Error{File: p.file, Line: p.number, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *tomlLine) skipSpace() {
+	p.rest = strings.TrimLeft(p.rest, " \t")
+}
+
+// Reports whether only a comment, if anything, is left
+func (p *tomlLine) done() bool {
+	return p.rest == "" || p.rest[0] == '#'
+}
+
+// Reads a key of dot separated parts and the space after it
+func (p *tomlLine) key() (string, error) {
+	var parts []string
+	for {
+		p.skipSpace()
+		var part string
+		switch {
+		case strings.HasPrefix(p.rest, `"`):
+			s, err := p.basicString()
+			if err != nil {
+				return "", err
+			}
+			part = s
+		case strings.HasPrefix(p.rest, "'"):
+			s, err := p.literalString()
+			if err != nil {
+				return "", err
+			}
+			part = s
+		default:
+			n := 0
+			for n < len(p.rest) && isBareKeyByte(p.rest[n]) {
+				n++
+			}
+			if n == 0 {
+				return "", p.errorf("want a key, found %q", p.rest)
+			}
+			part, p.rest = p.rest[:n], p.rest[n:]
+		}
+		parts = append(parts, part)
+		p.skipSpace()
+		if !strings.HasPrefix(p.rest, ".") {
+			return strings.Join(parts, "."), nil
+		}
+		p.rest = p.rest[1:]
+	}
+}
+
+func isBareKeyByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// Reads a value and returns it as text
+func (p *tomlLine) value() (string, error) {
+	switch {
+	case strings.HasPrefix(p.rest, `"""`), strings.HasPrefix(p.rest, "'''"):
+		return "", p.errorf("multi-line strings are not supported")
+	case strings.HasPrefix(p.rest, `"`):
+		return p.basicString()
+	case strings.HasPrefix(p.rest, "'"):
+		return p.literalString()
+	case strings.HasPrefix(p.rest, "["):
+		return "", p.errorf("arrays are not supported")
+	case strings.HasPrefix(p.rest, "{"):
+		return "", p.errorf("inline tables are not supported")
+	case p.rest == "":
+		return "", p.errorf("want a value after =")
+	}
+
+	// A bare value runs up to space or a comment
+	n := strings.IndexAny(p.rest, " \t#")
+	if n < 0 {
+		n = len(p.rest)
+	}
+	word := p.rest[:n]
+	p.rest = p.rest[n:]
+	switch word {
+	case "true", "false":
+		return word, nil
+	}
+	if number, ok := tomlNumber(word); ok {
+		return number, nil
+	}
+	if isBaseInteger(word) {
+		return "", p.errorf("%q is not a hexadecimal, octal or binary integer", word)
+	}
+	if len(word) >= 10 && word[4] == '-' && word[7] == '-' {
+		return "", p.errorf("dates are not supported")
+	}
+	return "", p.errorf("%q is not a value, strings need quotes", word)
+}
+
+// Checks an integer or float of TOML and returns it without underscores. An
+// underscore must be between two digits, and so must a dot. Hexadecimal, octal
+// and binary integers are returned in decimal
+func tomlNumber(word string) (string, bool) {
+	if isBaseInteger(word) {
+		return tomlBaseInteger(word)
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] == '.' && (i == 0 || i == len(word)-1 || !isDigit(word[i-1]) || !isDigit(word[i+1])) {
+			return "", false
+		}
+		if word[i] == '_' && (i == 0 || i == len(word)-1 || !isDigit(word[i-1]) || !isDigit(word[i+1])) {
+			return "", false
+		}
+	}
+	clean := strings.ReplaceAll(word, "_", "")
+	digits := clean
+	if digits != "" && (digits[0] == '+' || digits[0] == '-') {
+		digits = digits[1:]
+	}
+	switch {
+	case digits == "inf" || digits == "nan":
+		return clean, true
+	case digits == "" || !isDigit(digits[0]):
+		return "", false
+	case len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]):
+		// Leading zeros are not allowed
+		return "", false
+	}
+	if _, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return clean, true
+	}
+	// Go would also read hexadecimal floats and a dot without digits after it
+	if _, err := strconv.ParseFloat(clean, 64); err == nil && !strings.ContainsAny(clean, "xXpP") && !strings.HasSuffix(clean, ".") {
+		return clean, true
+	}
+	return "", false
+}
+
+// Reads an integer such as 0x1F, 0o17 or 0b1, which has no sign, and returns it
+// in decimal
+func tomlBaseInteger(word string) (string, bool) {
+	base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[word[1]]
+	digits := word[2:]
+	for i := 0; i < len(digits); i++ {
+		if digits[i] == '_' && (i == 0 || i == len(digits)-1 || digits[i-1] == '_' || digits[i+1] == '_') {
+			return "", false
+		}
+	}
+	clean := strings.ReplaceAll(digits, "_", "")
+	if clean[0] == '+' || clean[0] == '-' {
+		return "", false
+	}
+	n, err := strconv.ParseInt(clean, base, 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(n, 10), true
+}
+
+// Whether word starts like 0x1F, 0o17 or 0b1
+func isBaseInteger(word string) bool {
+	return len(word) > 2 && word[0] == '0' && strings.IndexByte("xob", word[1]) >= 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// Reads a string in double quotes with its escapes
+func (p *tomlLine) basicString() (string, error) {
+	var b strings.Builder
+	i := 1
+	for {
+		if i >= len(p.rest) {
+			return "", p.errorf("the string is not closed")
+		}
+		c := p.rest[i]
+		switch {
+		case c == '"':
+			p.rest = p.rest[i+1:]
+			return b.String(), nil
+		case c == '\\':
+			if i+1 >= len(p.rest) {
+				return "", p.errorf("the string is not closed")
+			}
+			escape := p.rest[i+1]
+			i += 2
+			switch escape {
+			case 'b':
+				b.WriteByte('\b')
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'f':
+				b.WriteByte('\f')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\':
+				b.WriteByte(escape)
+			case 'u', 'U':
+				size := 4
+				if escape == 'U' {
+					size = 8
+				}
+				if i+size > len(p.rest) {
+					return "", p.errorf("\\%c needs %d hex digits", escape, size)
+				}
+				code, err := strconv.ParseUint(p.rest[i:i+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", p.errorf("\\%c%s is not a character", escape, p.rest[i:i+size])
+				}
+				b.WriteRune(rune(code))
+				i += size
+			default:
+				return "", p.errorf("unknown escape \\%c", escape)
+			}
+		case c < 0x20 && c != '\t' || c == 0x7F:
+			return "", p.errorf("control character %U in a string", rune(c))
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// Reads a string in single quotes, which has no escapes
+func (p *tomlLine) literalString() (string, error) {
+	end := strings.IndexByte(p.rest[1:], '\'')
+	if end < 0 {
+		return "", p.errorf("the string is not closed")
+	}
+	s := p.rest[1 : end+1]
+	p.rest = p.rest[end+2:]
+	return s, nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	text := `# Settings for the greeter
+name = "Ada"   # who
+greeting = 'Hi, {name}\n'
+"quoted key" = "tab\tand é and \"quotes\""
+count = 1_000
+ratio = -0.5e3
+enabled = true
+mask = 0xdead_BEEF
+mode = 0o755
+bits = 0b1010
+
+[log]
+level = "debug"
+file.path = '/tmp/hello.log'
+
+[ server . http ]
+addr = ":8080"
+
+[server]
+name = "greeter"
+
+[log.file.rotate]
+daily = true
+`
+	want := []Entry{
+		{"name", "Ada", 2},
+		{"greeting", `Hi, {name}\n`, 3},
+		{"quoted key", "tab\tand é and \"quotes\"", 4},
+		{"count", "1000", 5},
+		{"ratio", "-0.5e3", 6},
+		{"enabled", "true", 7},
+		{"mask", "3735928559", 8},
+		{"mode", "493", 9},
+		{"bits", "10", 10},
+		{"log.level", "debug", 13},
+		{"log.file.path", "/tmp/hello.log", 14},
+		{"server.http.addr", ":8080", 17},
+		{"server.name", "greeter", 20},
+		{"log.file.rotate.daily", "true", 23},
+	}
+	got, err := ParseTOML("hello.toml", []byte(text))
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("ParseTOML == %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"name = Ada", `hello.toml:1: "Ada" is not a value, strings need quotes`},
+		{"\n\nname = \"Ada", "hello.toml:3: the string is not closed"},
+		{"a = 1\nb = 2\na = 3", `hello.toml:3: key "a" was already given on line 1`},
+		{"[log]\n[log]", "hello.toml:2: table [log] was already defined on line 1"},
+		{"a = 1\n[a]", `hello.toml:2: "a" is a value since line 1, not a table`},
+		{"a = 1\n[a.b]", `hello.toml:2: "a" is a value since line 1, not a table`},
+		{"a = 1\na.b = 2", `hello.toml:2: "a" is a value since line 1, not a table`},
+		{"a.b = 1\na = 2", `hello.toml:2: key "a" is a table since line 1`},
+		{"[a]\nb = 1\n[a.b]", `hello.toml:3: "a.b" is a value since line 2, not a table`},
+		{"[a]\nb.c = 1\n[a.b]", "hello.toml:3: table [a.b] was already defined by a dotted key on line 2"},
+		{"list = [1, 2]", "hello.toml:1: arrays are not supported"},
+		{"point = { x = 1 }", "hello.toml:1: inline tables are not supported"},
+		{"[[servers]]", "hello.toml:1: arrays of tables are not supported"},
+		{`text = """a"""`, "hello.toml:1: multi-line strings are not supported"},
+		{"when = 1979-05-27", "hello.toml:1: dates are not supported"},
+		{`name = "a\qb"`, `hello.toml:1: unknown escape \q`},
+		{"name \"Ada\"", `hello.toml:1: want = after the key "name"`},
+		{"name =", "hello.toml:1: want a value after ="},
+		{"n = 1 2", `hello.toml:1: unexpected "2" after the value`},
+		{"n = 012", `hello.toml:1: "012" is not a value, strings need quotes`},
+		{"n = 1__0", `hello.toml:1: "1__0" is not a value, strings need quotes`},
+		{"n = 0x1p3", `hello.toml:1: "0x1p3" is not a hexadecimal, octal or binary integer`},
+		{"n = 1.e5", `hello.toml:1: "1.e5" is not a value, strings need quotes`},
+		{"n = 1.", `hello.toml:1: "1." is not a value, strings need quotes`},
+		{"n = -0x1F", `hello.toml:1: "-0x1F" is not a value, strings need quotes`},
+		{"n = 0x_1F", `hello.toml:1: "0x_1F" is not a hexadecimal, octal or binary integer`},
+		{"n = 0x+1", `hello.toml:1: "0x+1" is not a hexadecimal, octal or binary integer`},
+		{"n = 0o8", `hello.toml:1: "0o8" is not a hexadecimal, octal or binary integer`},
+		{"= 1", `hello.toml:1: want a key, found "= 1"`},
+		{"[log", "hello.toml:1: want ] after the table name"},
+	}
+
+	for _, c := range cases {
+		_, err := ParseTOML("hello.toml", []byte(c.text))
+		var configErr *Error
+		if !errors.As(err, &configErr) || err.Error() != c.want {
+			t.Errorf("ParseTOML(%q) == %v, want %s", c.text, err, c.want)
+		}
+	}
+}
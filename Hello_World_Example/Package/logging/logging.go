@@ -0,0 +1,99 @@
+// Package logging sets up log/slog the same way for every example program, so
+// their diagnostics go to standard error as text or JSON that can be filtered
+// by level, while what the programs are for still goes to standard output
+/*
+func main() {
+	logger := logging.Setup()
+	logger.Info("starting", "example", "channels")
+	fmt.Println("Hello") // Output for the user stays on standard output
+}
+
+	$ example -log-level debug -log-format json
+*/
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Format is how records are written, it can be set as a flag
+type Format string
+
+const (
+	// FormatText writes records as key=value pairs, easy to read and to grep
+	FormatText Format = "text"
+	// FormatJSON writes every record as one JSON object on a line of its own
+	FormatJSON Format = "json"
+)
+
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set takes "text" or "json", anything else is an error
+func (f *Format) Set(s string) error {
+	switch Format(s) {
+	case FormatText, FormatJSON:
+		*f = Format(s)
+		return nil
+	}
+	return fmt.Errorf("unknown log format %q, want text or json", s)
+}
+
+// Options changes what a logger writes and how
+type Options struct {
+	// Level is the least important level that is written
+	Level  slog.Level
+	Format Format
+	// AddSource adds the file and line that logged every record
+	AddSource bool
+}
+
+// DefaultOptions are used by New and are the defaults of the flags
+var DefaultOptions = Options{Level: slog.LevelInfo, Format: FormatText, AddSource: true}
+
+// AddFlags adds the -log-level and -log-format flags to flags, they set the
+// fields of o, whose values now are the defaults
+/*
+opts := logging.DefaultOptions
+opts.AddFlags(flags)
+flags.Parse(args)
+logger := logging.NewWithOptions(stderr, opts)
+*/
+func (o *Options) AddFlags(flags *flag.FlagSet) {
+	flags.TextVar(&o.Level, "log-level", o.Level, "the least important messages to log: debug, info, warn or error")
+	if o.Format == "" {
+		o.Format = FormatText
+	}
+	flags.Var(&o.Format, "log-format", "how to write log messages: text or json")
+}
+
+// New returns a logger that writes to w with DefaultOptions
+func New(w io.Writer) *slog.Logger {
+	return NewWithOptions(w, DefaultOptions)
+}
+
+// NewWithOptions returns a logger that writes to w as opts asks for
+func NewWithOptions(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level, AddSource: opts.AddSource}
+	if opts.Format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, handlerOpts))
+	}
+	return slog.New(slog.NewTextHandler(w, handlerOpts))
+}
+
+// Setup is for programs without flags of their own. It adds the logging flags
+// to flag.CommandLine, parses the command line, makes the logger that writes
+// to standard error the default of slog and returns it
+func Setup() *slog.Logger {
+	opts := DefaultOptions
+	opts.AddFlags(flag.CommandLine)
+	flag.Parse()
+	logger := NewWithOptions(os.Stderr, opts)
+	slog.SetDefault(logger)
+	return logger
+}
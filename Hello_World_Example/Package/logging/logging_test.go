@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	cases := []struct {
+		args     []string
+		wantErr  bool
+		want     []string
+		dontWant []string
+	}{
+		{nil, false, []string{"level=INFO", "msg=info", "source="}, []string{"msg=debug"}},
+		{[]string{"-log-level", "debug"}, false, []string{"msg=debug", "msg=info"}, nil},
+		{[]string{"-log-level", "warn"}, false, []string{"msg=warn"}, []string{"msg=info"}},
+		{[]string{"-log-format", "json"}, false, []string{`"level":"INFO"`, `"msg":"info"`, `"source":{`}, nil},
+		{[]string{"-log-format", "yaml"}, true, nil, nil},
+		{[]string{"-log-level", "loud"}, true, nil, nil},
+	}
+
+	for _, c := range cases {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		opts := DefaultOptions
+		opts.AddFlags(flags)
+		if err := flags.Parse(c.args); (err != nil) != c.wantErr {
+			t.Errorf("Parse(%q) == %v", c.args, err)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		var b bytes.Buffer
+		logger := NewWithOptions(&b, opts)
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		for _, want := range c.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("%q: the log has no %s:\n%s", c.args, want, b.String())
+			}
+		}
+		for _, dontWant := range c.dontWant {
+			if strings.Contains(b.String(), dontWant) {
+				t.Errorf("%q: the log has %s:\n%s", c.args, dontWant, b.String())
+			}
+		}
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	var b bytes.Buffer
+	NewWithOptions(&b, Options{Format: FormatJSON}).Info("hello", "name", "Ada", "count", 3)
+	var record map[string]any
+	if err := json.Unmarshal(b.Bytes(), &record); err != nil || record["name"] != "Ada" || record["count"] != 3.0 {
+		t.Errorf("The JSON record is %s, %v", b.String(), err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	logger := slog.New(recorder)
+	logger.Debug("first", "n", 1)
+	request := logger.With("id", 7).WithGroup("request")
+	request.Info("second", "status", 200, slog.Group("client", "addr", "127.0.0.1"))
+	request.With("path", "/").Error("third")
+
+	records := recorder.Records()
+	if len(records) != 3 {
+		t.Fatalf("Recorder kept %d records, want 3", len(records))
+	}
+	cases := []struct {
+		message string
+		level   slog.Level
+		attrs   map[string]string
+	}{
+		{"first", slog.LevelDebug, map[string]string{"n": "1"}},
+		{"second", slog.LevelInfo, map[string]string{"id": "7", "request.status": "200", "request.client.addr": "127.0.0.1"}},
+		{"third", slog.LevelError, map[string]string{"id": "7", "request.path": "/"}},
+	}
+	for i, c := range cases {
+		r := records[i]
+		if r.Message != c.message || r.Level != c.level {
+			t.Errorf("Record %d is %q at %v, want %q at %v", i, r.Message, r.Level, c.message, c.level)
+		}
+		attrs := AttrMap(r)
+		if len(attrs) != len(c.attrs) {
+			t.Errorf("Record %d has %v, want %v", i, attrs, c.attrs)
+		}
+		for key, want := range c.attrs {
+			if got := attrs[key].String(); got != want {
+				t.Errorf("Record %d has %s=%q, want %q", i, key, got, want)
+			}
+		}
+	}
+
+	recorder.Level = slog.LevelWarn
+	logger.Info("dropped")
+	recorder.Reset()
+	if got := recorder.Records(); len(got) != 0 {
+		t.Errorf("Recorder has %d records after Reset", len(got))
+	}
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Recorder is a slog.Handler that keeps every record instead of writing it,
+// for tests that check what was logged. Attributes added with With are put
+// into the records, and groups become prefixes of the keys, "request.status"
+/*
+recorder := logging.NewRecorder()
+doWork(slog.New(recorder))
+for _, r := range recorder.Records() {
+	attrs := logging.AttrMap(r)
+}
+*/
+type Recorder struct {
+	// Shared by the handlers made with WithAttrs and WithGroup
+	mu      *sync.Mutex
+	records *[]slog.Record
+	attrs   []slog.Attr
+	group   string
+	// Level is the least important level that is kept, the zero value keeps
+	// debug records as well
+	Level slog.Leveler
+}
+
+// NewRecorder returns a Recorder that keeps records of every level
+func NewRecorder() *Recorder {
+	return &Recorder{mu: new(sync.Mutex), records: new([]slog.Record), Level: slog.LevelDebug}
+}
+
+func (h *Recorder) Enabled(_ context.Context, level slog.Level) bool {
+	return h.Level == nil || level >= h.Level.Level()
+}
+
+func (h *Recorder) Handle(_ context.Context, r slog.Record) error {
+	kept := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	kept.AddAttrs(h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		kept.AddAttrs(qualify(h.group, a))
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, kept)
+	return nil
+}
+
+func (h *Recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	next.attrs = append(next.attrs, h.attrs...)
+	for _, a := range attrs {
+		next.attrs = append(next.attrs, qualify(h.group, a))
+	}
+	return &next
+}
+
+func (h *Recorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.group = qualifyKey(h.group, name)
+	return &next
+}
+
+// Records returns a copy of the records kept so far, the oldest first
+func (h *Recorder) Records() []slog.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]slog.Record, len(*h.records))
+	for i, r := range *h.records {
+		out[i] = r.Clone()
+	}
+	return out
+}
+
+// Reset throws away the records kept so far
+func (h *Recorder) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = nil
+}
+
+// AttrMap returns the attributes of r by key. Groups are flattened, so the
+// attribute status in the group request is found under "request.status"
+func AttrMap(r slog.Record) map[string]slog.Value {
+	out := map[string]slog.Value{}
+	var add func(prefix string, a slog.Attr)
+	add = func(prefix string, a slog.Attr) {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			for _, member := range v.Group() {
+				add(qualifyKey(prefix, a.Key), member)
+			}
+			return
+		}
+		out[qualifyKey(prefix, a.Key)] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		add("", a)
+		return true
+	})
+	return out
+}
+
+func qualify(group string, a slog.Attr) slog.Attr {
+	a.Key = qualifyKey(group, a.Key)
+	return a
+}
+
+func qualifyKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	if key == "" {
+		// An inline group, its members go straight into group
+		return group
+	}
+	return group + "." + key
+}
@@ -0,0 +1,234 @@
+package utilities
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+)
+
+// MatchMode picks which matches a Matcher reports
+type MatchMode int
+
+const (
+	// MatchOverlapping reports every occurrence of every pattern, also those
+	// inside of or overlapping with other matches
+	MatchOverlapping MatchMode = iota
+	// MatchLeftmostLongest reports matches that do not overlap. Of the matches
+	// that start first it picks the longest, then it goes on after its end
+	MatchLeftmostLongest
+)
+
+// MatcherOptions changes how a Matcher searches
+type MatcherOptions struct {
+	// FoldCase matches patterns without regard to case using simple Unicode
+	// case folding, so "K" also matches U+212A KELVIN SIGN but "ß" never
+	// matches "ss"
+	FoldCase bool
+	Mode     MatchMode
+}
+
+// Match is a single occurrence of a pattern
+type Match struct {
+	// Pattern is the index of the pattern in the list given to NewMatcher
+	Pattern int
+	// Start and End are the byte offsets of the match in the input
+	Start, End int64
+	// RuneStart and RuneEnd are the same offsets counted in runes
+	RuneStart, RuneEnd int64
+}
+
+// Matcher finds many patterns at once in a single pass over the input using the
+// Aho-Corasick automaton. It is safe to use from many goroutines at once
+/*
+m := utilities.NewMatcher([]string{"he", "she", "his", "hers"}, utilities.MatcherOptions{})
+m.FindAll("ushers") // she at 1, he at 2 and hers at 2
+*/
+type Matcher struct {
+	opts       MatcherOptions
+	nodes      []acNode
+	patternLen []int // In runes
+	maxLen     int
+}
+
+// A state of the automaton, which is a node of the trie of all patterns
+type acNode struct {
+	next map[rune]int32
+	// The longest proper suffix of this node that is also in the trie
+	fail int32
+	// The pattern ending at this node, or -1
+	output int32
+	// The nearest node on the fail chain that has an output, or -1
+	dict int32
+}
+
+// NewMatcher builds a matcher for patterns. Empty patterns are left out, a
+// pattern listed twice is reported with the index of its first occurrence
+func NewMatcher(patterns []string, opts MatcherOptions) *Matcher {
+	m := &Matcher{
+		opts:       opts,
+		nodes:      []acNode{{output: -1, dict: -1}},
+		patternLen: make([]int, len(patterns)),
+	}
+
+	// The trie of all patterns
+	for i, pattern := range patterns {
+		node := int32(0)
+		for _, r := range pattern {
+			r = m.fold(r)
+			next, ok := m.nodes[node].next[r]
+			if !ok {
+				next = int32(len(m.nodes))
+				m.nodes = append(m.nodes, acNode{output: -1, dict: -1})
+				if m.nodes[node].next == nil {
+					m.nodes[node].next = map[rune]int32{}
+				}
+				m.nodes[node].next[r] = next
+			}
+			node = next
+			m.patternLen[i]++
+		}
+		if node != 0 && m.nodes[node].output < 0 {
+			m.nodes[node].output = int32(i)
+		}
+		m.maxLen = max(m.maxLen, m.patternLen[i])
+	}
+
+	// Fail links are found breadth first, since they always lead to a node
+	// closer to the root
+	queue := []int32{0}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[node].next {
+			if node != 0 {
+				m.nodes[child].fail = m.step(m.nodes[node].fail, r)
+			}
+			fail := m.nodes[child].fail
+			if m.nodes[fail].output >= 0 {
+				m.nodes[child].dict = fail
+			} else {
+				m.nodes[child].dict = m.nodes[fail].dict
+			}
+			queue = append(queue, child)
+		}
+	}
+	return m
+}
+
+func (m *Matcher) fold(r rune) rune {
+	if m.opts.FoldCase {
+		return foldRune(r)
+	}
+	return r
+}
+
+// Returns the state after reading r in node
+func (m *Matcher) step(node int32, r rune) int32 {
+	for {
+		if next, ok := m.nodes[node].next[r]; ok {
+			return next
+		}
+		if node == 0 {
+			return 0
+		}
+		node = m.nodes[node].fail
+	}
+}
+
+// FindAll returns all the matches in s
+func (m *Matcher) FindAll(s string) []Match {
+	var matches []Match
+	m.Scan(strings.NewReader(s), func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	return matches
+}
+
+// Scan reads r until its end and calls fn with every match in the order they
+// end in, matches ending at the same place come longest first. Scanning stops
+// early when fn returns false. Only the last runes that could still be part of
+// a match are kept in memory, so r may be a stream of any length. Bytes that are
+// not valid UTF-8 are read as U+FFFD. The error is the first one r returned
+// other than io.EOF
+func (m *Matcher) Scan(r io.Reader, fn func(Match) bool) error {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+
+	// The byte offsets of the last maxLen runes, to find where matches start
+	starts := make([]int64, max(m.maxLen, 1))
+	var bytePos, runePos int64
+	state := int32(0)
+
+	// Leftmost-longest matches wait here until no later match can beat them
+	var pending []Match
+	var cutoff int64 // Matches starting before this rune overlap one handed out
+	release := func(atEOF bool) bool {
+		for {
+			best := -1
+			for i, p := range pending {
+				if best < 0 || p.RuneStart < pending[best].RuneStart ||
+					(p.RuneStart == pending[best].RuneStart && p.RuneEnd > pending[best].RuneEnd) {
+					best = i
+				}
+			}
+			if best < 0 {
+				return true
+			}
+			b := pending[best]
+			// A match found later ends after runePos, so it starts after runePos-maxLen
+			if !atEOF && runePos+1-int64(m.maxLen) <= b.RuneStart {
+				return true
+			}
+			if !fn(b) {
+				return false
+			}
+			cutoff = b.RuneEnd
+			pending = slices.DeleteFunc(pending, func(p Match) bool { return p.RuneStart < cutoff })
+		}
+	}
+
+	for {
+		c, size, err := rr.ReadRune()
+		if err == io.EOF {
+			release(true)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		starts[runePos%int64(len(starts))] = bytePos
+		bytePos += int64(size)
+		runePos++
+
+		state = m.step(state, m.fold(c))
+		node := state
+		if m.nodes[node].output < 0 {
+			node = m.nodes[node].dict
+		}
+		for ; node >= 0; node = m.nodes[node].dict {
+			pattern := int(m.nodes[node].output)
+			start := runePos - int64(m.patternLen[pattern])
+			match := Match{
+				Pattern:   pattern,
+				Start:     starts[start%int64(len(starts))],
+				End:       bytePos,
+				RuneStart: start,
+				RuneEnd:   runePos,
+			}
+			if m.opts.Mode == MatchOverlapping {
+				if !fn(match) {
+					return nil
+				}
+			} else if start >= cutoff {
+				pending = append(pending, match)
+			}
+		}
+		if m.opts.Mode == MatchLeftmostLongest && !release(false) {
+			return nil
+		}
+	}
+}
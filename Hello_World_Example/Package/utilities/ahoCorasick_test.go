@@ -0,0 +1,123 @@
+package utilities
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMatcherModes(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		in       string
+		mode     MatchMode
+		want     []Match
+	}{
+		{[]string{"he", "she", "his", "hers"}, "ushers", MatchOverlapping, []Match{
+			{1, 1, 4, 1, 4}, {0, 2, 4, 2, 4}, {3, 2, 6, 2, 6},
+		}},
+		{[]string{"he", "she", "his", "hers"}, "ushers", MatchLeftmostLongest, []Match{
+			{1, 1, 4, 1, 4},
+		}},
+		// The longest of the matches starting at 0 wins
+		{[]string{"a", "ab", "abc", "bcd"}, "abcd", MatchLeftmostLongest, []Match{
+			{2, 0, 3, 0, 3},
+		}},
+		// abcd never finishes, so b is handed out after all
+		{[]string{"b", "abcd"}, "abcx", MatchLeftmostLongest, []Match{
+			{0, 1, 2, 1, 2},
+		}},
+		{[]string{"aa"}, "aaaa", MatchOverlapping, []Match{
+			{0, 0, 2, 0, 2}, {0, 1, 3, 1, 3}, {0, 2, 4, 2, 4},
+		}},
+		{[]string{"aa"}, "aaaa", MatchLeftmostLongest, []Match{
+			{0, 0, 2, 0, 2}, {0, 2, 4, 2, 4},
+		}},
+		// Byte and rune offsets part ways after the first wide character
+		{[]string{"世界", "!"}, "Hello, 世界!", MatchOverlapping, []Match{
+			{0, 7, 13, 7, 9}, {1, 13, 14, 9, 10},
+		}},
+		{[]string{"", "x"}, "abc", MatchOverlapping, nil},
+	}
+
+	for _, c := range cases {
+		m := NewMatcher(c.patterns, MatcherOptions{Mode: c.mode})
+		got := m.FindAll(c.in)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("NewMatcher(%q, %d).FindAll(%q) == %v, want %v", c.patterns, c.mode, c.in, got, c.want)
+		}
+	}
+}
+
+func TestMatcherFoldCase(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		in       string
+		want     []Match
+	}{
+		{[]string{"hello"}, "HeLLo", []Match{{0, 0, 5, 0, 5}}},
+		// U+212A KELVIN SIGN folds to k
+		{[]string{"kelvin"}, "Kelvin", []Match{{0, 0, 8, 0, 6}}},
+		{[]string{"ΣΟΦΙΑ"}, "σοφια", []Match{{0, 0, 10, 0, 5}}},
+	}
+
+	for _, c := range cases {
+		m := NewMatcher(c.patterns, MatcherOptions{FoldCase: true})
+		got := m.FindAll(c.in)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("FindAll(%q) with %q folded == %v, want %v", c.in, c.patterns, got, c.want)
+		}
+		if got := NewMatcher(c.patterns, MatcherOptions{}).FindAll(c.in); got != nil {
+			t.Errorf("FindAll(%q) with %q == %v, want no matches", c.in, c.patterns, got)
+		}
+	}
+}
+
+func TestMatcherScan(t *testing.T) {
+	patterns := []string{"fox", "the", "he", "lazy dog", "ü"}
+	in := strings.Repeat("The quick brown fox jumps over the lazy dog, über \xff", 100)
+	for _, mode := range []MatchMode{MatchOverlapping, MatchLeftmostLongest} {
+		m := NewMatcher(patterns, MatcherOptions{FoldCase: true, Mode: mode})
+		want := m.FindAll(in)
+		if len(want) == 0 {
+			t.Fatalf("FindAll found nothing in mode %d", mode)
+		}
+
+		// One byte at a time, so runes are split across reads
+		var got []Match
+		err := m.Scan(iotest.OneByteReader(strings.NewReader(in)), func(match Match) bool {
+			got = append(got, match)
+			return true
+		})
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("Scan in mode %d == %d matches, %v, want the %d of FindAll", mode, len(got), err, len(want))
+		}
+
+		for _, match := range got {
+			if text := in[match.Start:match.End]; !strings.EqualFold(text, patterns[match.Pattern]) {
+				t.Errorf("Match %v covers %q, want %q", match, text, patterns[match.Pattern])
+			}
+		}
+	}
+}
+
+func TestMatcherScanStops(t *testing.T) {
+	m := NewMatcher([]string{"a"}, MatcherOptions{})
+	n := 0
+	err := m.Scan(strings.NewReader("aaaa"), func(Match) bool {
+		n++
+		return n < 2
+	})
+	if err != nil || n != 2 {
+		t.Errorf("Scan stopped after %d matches with %v, want 2 and no error", n, err)
+	}
+
+	broken := errors.New("broken")
+	r := io.MultiReader(strings.NewReader("a"), iotest.ErrReader(broken))
+	if err := m.Scan(r, func(Match) bool { return true }); err != broken {
+		t.Errorf("Scan of a failing reader == %v, want %v", err, broken)
+	}
+}
@@ -0,0 +1,779 @@
+package utilities
+
+import (
+	"slices"
+	"strings"
+)
+
+// The tables in bidiTables.go are generated from the Unicode Character Database
+//go:generate go run genBidiTables.go
+
+// BidiDirection is the base direction of a paragraph of text
+type BidiDirection int
+
+const (
+	// BidiAuto takes the direction from the first strong letter of every
+	// paragraph, rules P2 and P3 of UAX #9
+	BidiAuto BidiDirection = iota
+	// BidiLeftToRight is the direction of Latin text
+	BidiLeftToRight
+	// BidiRightToLeft is the direction of Hebrew and Arabic text
+	BidiRightToLeft
+)
+
+// Bidi_Class property values from UAX #9
+type bidiClass uint8
+
+const (
+	bidiL bidiClass = iota
+	bidiR
+	bidiAL
+	bidiEN
+	bidiES
+	bidiET
+	bidiAN
+	bidiCS
+	bidiNSM
+	bidiBN
+	bidiB
+	bidiS
+	bidiWS
+	bidiON
+	bidiLRE
+	bidiLRO
+	bidiRLE
+	bidiRLO
+	bidiPDF
+	bidiLRI
+	bidiRLI
+	bidiFSI
+	bidiPDI
+)
+
+type bidiRange struct {
+	lo, hi rune
+	class  bidiClass
+}
+
+type bidiBracket struct {
+	r, pair rune
+	open    bool
+}
+
+// The deepest explicit embedding level, rule BD2
+const bidiMaxDepth = 125
+
+// The deepest bracket nesting looked at by rule BD16
+const bidiMaxBracketDepth = 63
+
+// Binary searches the generated table for the class of r, L is the default
+func bidiClassOf(r rune) bidiClass {
+	lo, hi := 0, len(bidiClassTable)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch e := &bidiClassTable[m]; {
+		case r < e.lo:
+			hi = m
+		case r > e.hi:
+			lo = m + 1
+		default:
+			return e.class
+		}
+	}
+	return bidiL
+}
+
+// Returns the bracket entry of r if r is a paired bracket
+func bidiBracketOf(r rune) (bidiBracket, bool) {
+	i, found := slices.BinarySearchFunc(bidiBracketTable[:], r, func(b bidiBracket, r rune) int {
+		return int(b.r - r)
+	})
+	if !found {
+		return bidiBracket{}, false
+	}
+	return bidiBracketTable[i], true
+}
+
+// Returns the opening bracket that identifies the pair r belongs to. The angle
+// brackets U+2329 and U+232A are canonically equivalent to U+3008 and U+3009,
+// BD16 wants them to match each other
+func bidiBracketID(b bidiBracket) rune {
+	id := b.r
+	if !b.open {
+		id = b.pair
+	}
+	if id == 0x2329 {
+		id = 0x3008
+	}
+	return id
+}
+
+// Characters that rule X9 takes out of the text
+func bidiRemoved(c bidiClass) bool {
+	switch c {
+	case bidiLRE, bidiRLE, bidiLRO, bidiRLO, bidiPDF, bidiBN:
+		return true
+	}
+	return false
+}
+
+func bidiIsolateInitiator(c bidiClass) bool {
+	return c == bidiLRI || c == bidiRLI || c == bidiFSI
+}
+
+// Strong type matching the direction of level
+func bidiClassForLevel(level int8) bidiClass {
+	if level%2 == 1 {
+		return bidiR
+	}
+	return bidiL
+}
+
+// BidiLevels runs the Unicode Bidirectional Algorithm, UAX #9, over s and returns
+// the embedding level of every rune, every paragraph is treated as a single line.
+// Even levels are displayed left to right and odd levels right to left. The
+// explicit formatting characters removed by rule X9 get a level of -1
+func BidiLevels(s string, dir BidiDirection) []int {
+	var levels []int
+	for paragraph := range bidiParagraphs(s) {
+		p := newBidiParagraph([]rune(paragraph), dir)
+		for i, level := range p.lineLevels() {
+			if bidiRemoved(p.classes[i]) {
+				levels = append(levels, -1)
+			} else {
+				levels = append(levels, int(level))
+			}
+		}
+	}
+	return levels
+}
+
+// VisualOrder returns s in the order it is displayed in, from left to right, as
+// worked out by the Unicode Bidirectional Algorithm. Every paragraph is treated
+// as a single line and keeps its paragraph separator at the end. Grapheme
+// clusters are moved as a whole so combining marks stay after their base, and
+// paired brackets in right to left runs are mirrored
+/*
+utilities.VisualOrder("abc אבג", utilities.BidiAuto) == "abc גבא"
+*/
+func VisualOrder(s string, dir BidiDirection) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for paragraph := range bidiParagraphs(s) {
+		runes := []rune(paragraph)
+		p := newBidiParagraph(runes, dir)
+		levels := p.lineLevels()
+
+		// The separator stays at the end of the line
+		text := paragraph
+		var separator string
+		if n := len(runes); n > 0 && p.classes[n-1] == bidiB {
+			separator = string(runes[n-1])
+			text = strings.TrimSuffix(paragraph, separator)
+		}
+
+		// Every cluster takes the level of its first rune
+		var clusters []string
+		var clusterLevels []int8
+		i := 0
+		for g := range Graphemes(text) {
+			clusters = append(clusters, g)
+			clusterLevels = append(clusterLevels, levels[i])
+			i += len([]rune(g))
+		}
+
+		for _, j := range bidiReorder(clusterLevels) {
+			g := clusters[j]
+			if clusterLevels[j]%2 == 1 {
+				g = mirrorBrackets(g)
+			}
+			b.WriteString(g)
+		}
+		b.WriteString(separator)
+	}
+	return b.String()
+}
+
+// Swaps every paired bracket in s for its partner, rule L4
+func mirrorBrackets(s string) string {
+	return strings.Map(func(r rune) rune {
+		if b, ok := bidiBracketOf(r); ok {
+			return b.pair
+		}
+		return r
+	}, s)
+}
+
+// Splits s into paragraphs, each one ends after a paragraph separator (class B)
+func bidiParagraphs(s string) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		start := 0
+		for i, r := range s {
+			if bidiClassOf(r) == bidiB {
+				end := i + len(string(r))
+				if !yield(s[start:end]) {
+					return
+				}
+				start = end
+			}
+		}
+		if start < len(s) {
+			yield(s[start:])
+		}
+	}
+}
+
+// The state of a single paragraph while the rules are applied to it
+type bidiParagraph struct {
+	runes   []rune
+	classes []bidiClass // The original class of every rune
+	types   []bidiClass // The class as changed by the rules
+	levels  []int8
+	level   int8 // The paragraph embedding level
+
+	// For isolate initiators the index of the matching PDI, or the length
+	// of the paragraph when there is none. For PDIs the index of the matching
+	// initiator. -1 everywhere else
+	matchingPDI       []int
+	matchingInitiator []int
+}
+
+// Resolves the embedding levels of a single paragraph, rules P2 through I2
+func newBidiParagraph(runes []rune, dir BidiDirection) *bidiParagraph {
+	n := len(runes)
+	p := &bidiParagraph{
+		runes:             runes,
+		classes:           make([]bidiClass, n),
+		types:             make([]bidiClass, n),
+		levels:            make([]int8, n),
+		matchingPDI:       make([]int, n),
+		matchingInitiator: make([]int, n),
+	}
+	for i, r := range runes {
+		p.classes[i] = bidiClassOf(r)
+	}
+	copy(p.types, p.classes)
+	p.matchIsolates()
+
+	switch dir {
+	case BidiLeftToRight:
+		p.level = 0
+	case BidiRightToLeft:
+		p.level = 1
+	default:
+		p.level, _ = p.firstStrong(0, n)
+	}
+
+	p.explicitLevels()
+	for _, seq := range p.isolatingRunSequences() {
+		seq.resolveWeakTypes()
+		seq.resolveBrackets()
+		seq.resolveNeutralTypes()
+		seq.resolveImplicitLevels()
+	}
+
+	// The characters removed by X9 take the level of the character before
+	// them so that they do not split a run
+	for i, c := range p.classes {
+		if bidiRemoved(c) {
+			if i == 0 {
+				p.levels[i] = p.level
+			} else {
+				p.levels[i] = p.levels[i-1]
+			}
+		}
+	}
+	return p
+}
+
+// Pairs up isolate initiators with their PDI, rule BD9
+func (p *bidiParagraph) matchIsolates() {
+	var open []int
+	for i, c := range p.classes {
+		p.matchingPDI[i] = -1
+		p.matchingInitiator[i] = -1
+		switch {
+		case bidiIsolateInitiator(c):
+			p.matchingPDI[i] = len(p.classes)
+			open = append(open, i)
+		case c == bidiPDI && len(open) > 0:
+			j := open[len(open)-1]
+			open = open[:len(open)-1]
+			p.matchingPDI[j] = i
+			p.matchingInitiator[i] = j
+		}
+	}
+}
+
+// Returns the level of the first strong character between start and end,
+// skipping over isolates, rules P2 and P3. found is false when there is none
+func (p *bidiParagraph) firstStrong(start, end int) (level int8, found bool) {
+	for i := start; i < end; i++ {
+		switch c := p.classes[i]; {
+		case c == bidiL:
+			return 0, true
+		case c == bidiR || c == bidiAL:
+			return 1, true
+		case bidiIsolateInitiator(c):
+			i = p.matchingPDI[i]
+		}
+	}
+	return 0, false
+}
+
+// Applies the explicit embeddings, overrides and isolates, rules X1 through X8
+func (p *bidiParagraph) explicitLevels() {
+	type status struct {
+		level    int8
+		override bidiClass // L, R or ON for no override
+		isolate  bool
+	}
+	stack := make([]status, 1, bidiMaxDepth+2)
+	stack[0] = status{p.level, bidiON, false}
+	overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+
+	for i, c := range p.classes {
+		top := stack[len(stack)-1]
+		switch c {
+		case bidiRLE, bidiLRE, bidiRLO, bidiLRO, bidiRLI, bidiLRI, bidiFSI:
+			isolate := bidiIsolateInitiator(c)
+			rtl := c == bidiRLE || c == bidiRLO || c == bidiRLI
+			if c == bidiFSI {
+				level, _ := p.firstStrong(i+1, p.matchingPDI[i])
+				rtl = level == 1
+			}
+			p.levels[i] = top.level
+			if isolate && top.override != bidiON {
+				p.types[i] = top.override
+			}
+
+			next := (top.level + 2) &^ 1
+			if rtl {
+				next = (top.level + 1) | 1
+			}
+			if next <= bidiMaxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				entry := status{next, bidiON, isolate}
+				if c == bidiLRO {
+					entry.override = bidiL
+				} else if c == bidiRLO {
+					entry.override = bidiR
+				}
+				if isolate {
+					validIsolates++
+				}
+				stack = append(stack, entry)
+			} else if isolate {
+				overflowIsolates++
+			} else if overflowIsolates == 0 {
+				overflowEmbeddings++
+			}
+
+		case bidiPDI:
+			if overflowIsolates > 0 {
+				overflowIsolates--
+			} else if validIsolates > 0 {
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			top = stack[len(stack)-1]
+			p.levels[i] = top.level
+			if top.override != bidiON {
+				p.types[i] = top.override
+			}
+
+		case bidiPDF:
+			p.levels[i] = top.level
+			if overflowIsolates > 0 {
+				// Inside of an overflowing isolate the PDF does nothing
+			} else if overflowEmbeddings > 0 {
+				overflowEmbeddings--
+			} else if !top.isolate && len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+
+		case bidiB:
+			p.levels[i] = p.level
+
+		case bidiBN:
+			p.levels[i] = top.level
+
+		default:
+			p.levels[i] = top.level
+			if top.override != bidiON {
+				p.types[i] = top.override
+			}
+		}
+	}
+}
+
+// An isolating run sequence, the unit rules W1 through I2 work on
+type bidiSequence struct {
+	p        *bidiParagraph
+	indexes  []int       // Positions of the characters in the paragraph
+	types    []bidiClass // Types of the characters at those positions
+	level    int8
+	sos, eos bidiClass
+}
+
+// Splits the paragraph into level runs and joins the runs that are connected
+// through isolates, rules BD13 and X10
+func (p *bidiParagraph) isolatingRunSequences() []*bidiSequence {
+	var runs [][]int
+	runOf := make([]int, len(p.classes))
+	for i, c := range p.classes {
+		if bidiRemoved(c) {
+			continue
+		}
+		n := len(runs)
+		if n == 0 || p.levels[runs[n-1][0]] != p.levels[i] {
+			runs = append(runs, nil)
+			n++
+		}
+		runs[n-1] = append(runs[n-1], i)
+		runOf[i] = n - 1
+	}
+
+	var sequences []*bidiSequence
+	for _, run := range runs {
+		// Runs starting with a matched PDI continue the sequence of their initiator
+		if first := run[0]; p.classes[first] == bidiPDI && p.matchingInitiator[first] >= 0 {
+			continue
+		}
+		var indexes []int
+		for {
+			indexes = append(indexes, run...)
+			last := indexes[len(indexes)-1]
+			if !bidiIsolateInitiator(p.classes[last]) || p.matchingPDI[last] == len(p.classes) {
+				break
+			}
+			run = runs[runOf[p.matchingPDI[last]]]
+		}
+		sequences = append(sequences, p.newSequence(indexes))
+	}
+	return sequences
+}
+
+// Works out the level, sos and eos of the sequence made up of indexes
+func (p *bidiParagraph) newSequence(indexes []int) *bidiSequence {
+	seq := &bidiSequence{
+		p:       p,
+		indexes: indexes,
+		types:   make([]bidiClass, len(indexes)),
+		level:   p.levels[indexes[0]],
+	}
+	for i, x := range indexes {
+		seq.types[i] = p.types[x]
+	}
+
+	before := p.level
+	for i := indexes[0] - 1; i >= 0; i-- {
+		if !bidiRemoved(p.classes[i]) {
+			before = p.levels[i]
+			break
+		}
+	}
+	after := p.level
+	if last := indexes[len(indexes)-1]; !bidiIsolateInitiator(p.classes[last]) {
+		for i := last + 1; i < len(p.classes); i++ {
+			if !bidiRemoved(p.classes[i]) {
+				after = p.levels[i]
+				break
+			}
+		}
+	}
+	seq.sos = bidiClassForLevel(max(before, seq.level))
+	seq.eos = bidiClassForLevel(max(after, seq.level))
+	return seq
+}
+
+// Returns the end of the run of types from the set that starts at i
+func (seq *bidiSequence) runEnd(i int, set ...bidiClass) int {
+	for i < len(seq.types) && slices.Contains(set, seq.types[i]) {
+		i++
+	}
+	return i
+}
+
+// Rules W1 through W7
+func (seq *bidiSequence) resolveWeakTypes() {
+	types := seq.types
+
+	// W1, nonspacing marks take the type of the character before them
+	previous := seq.sos
+	for i, t := range types {
+		switch {
+		case t == bidiNSM:
+			types[i] = previous
+		case bidiIsolateInitiator(t) || t == bidiPDI:
+			previous = bidiON
+		default:
+			previous = t
+		}
+	}
+
+	// W2, European numbers after Arabic letters become Arabic numbers
+	last := seq.sos
+	for i, t := range types {
+		switch t {
+		case bidiL, bidiR, bidiAL:
+			last = t
+		case bidiEN:
+			if last == bidiAL {
+				types[i] = bidiAN
+			}
+		}
+	}
+
+	// W3
+	for i, t := range types {
+		if t == bidiAL {
+			types[i] = bidiR
+		}
+	}
+
+	// W4, single separators between two numbers of the same kind
+	for i := 1; i < len(types)-1; i++ {
+		before, after := types[i-1], types[i+1]
+		switch types[i] {
+		case bidiES:
+			if before == bidiEN && after == bidiEN {
+				types[i] = bidiEN
+			}
+		case bidiCS:
+			if before == after && (before == bidiEN || before == bidiAN) {
+				types[i] = before
+			}
+		}
+	}
+
+	// W5, terminators next to European numbers
+	for i := 0; i < len(types); i++ {
+		if types[i] != bidiET {
+			continue
+		}
+		end := seq.runEnd(i, bidiET)
+		if (i > 0 && types[i-1] == bidiEN) || (end < len(types) && types[end] == bidiEN) {
+			for j := i; j < end; j++ {
+				types[j] = bidiEN
+			}
+		}
+		i = end
+	}
+
+	// W6
+	for i, t := range types {
+		if t == bidiES || t == bidiET || t == bidiCS {
+			types[i] = bidiON
+		}
+	}
+
+	// W7, European numbers in Latin text become L
+	last = seq.sos
+	for i, t := range types {
+		switch t {
+		case bidiL, bidiR:
+			last = t
+		case bidiEN:
+			if last == bidiL {
+				types[i] = bidiL
+			}
+		}
+	}
+}
+
+// Rule N0, paired brackets take the direction of their content or context
+func (seq *bidiSequence) resolveBrackets() {
+	type opener struct {
+		id  rune
+		pos int
+	}
+	type pair struct{ open, close int }
+
+	// BD16, find the bracket pairs
+	var stack []opener
+	var pairs []pair
+find:
+	for i, x := range seq.indexes {
+		if seq.types[i] != bidiON {
+			continue
+		}
+		b, ok := bidiBracketOf(seq.p.runes[x])
+		if !ok {
+			continue
+		}
+		if b.open {
+			if len(stack) == bidiMaxBracketDepth {
+				break find
+			}
+			stack = append(stack, opener{bidiBracketID(b), i})
+			continue
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].id == bidiBracketID(b) {
+				pairs = append(pairs, pair{stack[j].pos, i})
+				stack = stack[:j]
+				break
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(a, b pair) int { return a.open - b.open })
+
+	// Numbers count as R inside of this rule
+	strong := func(i int) bidiClass {
+		switch seq.types[i] {
+		case bidiL:
+			return bidiL
+		case bidiR, bidiAL, bidiEN, bidiAN:
+			return bidiR
+		}
+		return bidiON
+	}
+	embedding := bidiClassForLevel(seq.level)
+
+	for _, pr := range pairs {
+		inside := bidiON
+		for i := pr.open + 1; i < pr.close; i++ {
+			if s := strong(i); s == embedding {
+				inside = s
+				break
+			} else if s != bidiON {
+				inside = s
+			}
+		}
+		if inside == bidiON {
+			// N0 d, nothing strong inside, N1 and N2 take care of the pair
+			continue
+		}
+		direction := embedding
+		if inside != embedding {
+			// N0 c, use the opposite direction when the context before agrees
+			context := seq.sos
+			for i := pr.open - 1; i >= 0; i-- {
+				if s := strong(i); s != bidiON {
+					context = s
+					break
+				}
+			}
+			if context == inside {
+				direction = inside
+			}
+		}
+
+		// Marks that followed the brackets change along with them
+		for _, pos := range []int{pr.open, pr.close} {
+			seq.types[pos] = direction
+			for i := pos + 1; i < len(seq.indexes) && seq.p.classes[seq.indexes[i]] == bidiNSM; i++ {
+				seq.types[i] = direction
+			}
+		}
+	}
+}
+
+// Rules N1 and N2, neutrals between two strong types of the same direction
+// take that direction, all other neutrals take the embedding direction
+func (seq *bidiSequence) resolveNeutralTypes() {
+	types := seq.types
+	neutral := []bidiClass{bidiB, bidiS, bidiWS, bidiON, bidiLRI, bidiRLI, bidiFSI, bidiPDI}
+	asStrong := func(t bidiClass) bidiClass {
+		if t == bidiEN || t == bidiAN {
+			return bidiR
+		}
+		return t
+	}
+
+	for i := 0; i < len(types); i++ {
+		if !slices.Contains(neutral, types[i]) {
+			continue
+		}
+		end := seq.runEnd(i, neutral...)
+		before, after := seq.sos, seq.eos
+		if i > 0 {
+			before = asStrong(types[i-1])
+		}
+		if end < len(types) {
+			after = asStrong(types[end])
+		}
+		direction := bidiClassForLevel(seq.level)
+		if before == after {
+			direction = before
+		}
+		for j := i; j < end; j++ {
+			types[j] = direction
+		}
+		i = end
+	}
+}
+
+// Rules I1 and I2, the final level of every character of the sequence
+func (seq *bidiSequence) resolveImplicitLevels() {
+	for i, t := range seq.types {
+		level := seq.level
+		switch {
+		case level%2 == 0 && t == bidiR:
+			level++
+		case level%2 == 0 && (t == bidiAN || t == bidiEN):
+			level += 2
+		case level%2 == 1 && t != bidiR:
+			level++
+		}
+		x := seq.indexes[i]
+		seq.p.types[x] = t
+		seq.p.levels[x] = level
+	}
+}
+
+// Returns the levels with rule L1 applied for a paragraph shown on a single line,
+// separators and the white space before them or at the end go back to the
+// paragraph level
+func (p *bidiParagraph) lineLevels() []int8 {
+	levels := slices.Clone(p.levels)
+	whitespace := func(c bidiClass) bool {
+		return c == bidiWS || bidiIsolateInitiator(c) || c == bidiPDI || bidiRemoved(c)
+	}
+	reset := func(end int) {
+		for j := end - 1; j >= 0 && whitespace(p.classes[j]); j-- {
+			levels[j] = p.level
+		}
+	}
+	for i, c := range p.classes {
+		if c == bidiS || c == bidiB {
+			levels[i] = p.level
+			reset(i)
+		}
+	}
+	reset(len(levels))
+	return levels
+}
+
+// Returns the logical positions in visual order from left to right, rule L2
+// reverses every run at or above each odd level, starting with the highest
+func bidiReorder(levels []int8) []int {
+	order := make([]int, len(levels))
+	highest, lowestOdd := int8(0), int8(bidiMaxDepth+2)
+	for i, level := range levels {
+		order[i] = i
+		highest = max(highest, level)
+		if level%2 == 1 {
+			lowestOdd = min(lowestOdd, level)
+		}
+	}
+
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(levels); i++ {
+			if levels[i] < level {
+				continue
+			}
+			end := i
+			for end < len(levels) && levels[end] >= level {
+				end++
+			}
+			slices.Reverse(order[i:end])
+			i = end
+		}
+	}
+	return order
+}
@@ -0,0 +1,124 @@
+package utilities
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestVisualOrder(t *testing.T) {
+	cases := []struct {
+		in   string
+		dir  BidiDirection
+		want string
+	}{
+		{"", BidiAuto, ""},
+		{"Hello, world", BidiAuto, "Hello, world"},
+		// Hebrew אבג after Latin text
+		{"abc אבג", BidiAuto, "abc גבא"},
+		// The same text in a right to left paragraph
+		{"abc אבג", BidiRightToLeft, "גבא abc"},
+		// Numbers keep their order inside of Hebrew text
+		{"א 123 ב", BidiAuto, "ב 123 א"},
+		// Brackets are mirrored in right to left runs
+		{"א (ב) ג", BidiAuto, "ג (ב) א"},
+		// Latin inside of an RLI ... PDI isolate keeps its order
+		{"א ⁧abc⁩ ב", BidiAuto, "ב ⁩abc⁧ א"},
+		// The combining mark stays after its base
+		{"אָב", BidiAuto, "באָ"},
+		// Every paragraph is reordered by itself
+		{"אב\nabc", BidiAuto, "בא\nabc"},
+	}
+
+	for _, c := range cases {
+		got := VisualOrder(c.in, c.dir)
+		if got != c.want {
+			t.Errorf("VisualOrder(%+q, %d) == %+q, want %+q", c.in, c.dir, got, c.want)
+		}
+	}
+}
+
+func TestBidiLevels(t *testing.T) {
+	cases := []struct {
+		in   string
+		dir  BidiDirection
+		want []int
+	}{
+		{"aא", BidiLeftToRight, []int{0, 1}},
+		{"aא", BidiRightToLeft, []int{2, 1}},
+		// U+202B RIGHT-TO-LEFT EMBEDDING and U+202C POP DIRECTIONAL FORMATTING are removed
+		{"a‫b‬", BidiAuto, []int{0, -1, 2, -1}},
+		{"א 12", BidiAuto, []int{1, 1, 2, 2}},
+	}
+
+	for _, c := range cases {
+		got := BidiLevels(c.in, c.dir)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("BidiLevels(%+q, %d) == %v, want %v", c.in, c.dir, got, c.want)
+		}
+	}
+}
+
+// Runs every case of the conformance file in testdata, see the header of the
+// file for where the cases come from and what every field means
+func TestBidiConformance(t *testing.T) {
+	f, err := os.Open("testdata/BidiCharacterTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	directions := map[string]BidiDirection{"0": BidiLeftToRight, "1": BidiRightToLeft, "2": BidiAuto}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields := strings.Split(text, ";")
+		if len(fields) != 5 {
+			t.Fatalf("line %d: want 5 fields, got %d", line, len(fields))
+		}
+		var runes []rune
+		for _, field := range strings.Fields(fields[0]) {
+			cp, err := strconv.ParseUint(field, 16, 32)
+			if err != nil {
+				t.Fatalf("line %d: %v", line, err)
+			}
+			runes = append(runes, rune(cp))
+		}
+
+		p := newBidiParagraph(runes, directions[fields[1]])
+		if got := strconv.Itoa(int(p.level)); got != fields[2] {
+			t.Errorf("line %d: paragraph level %s, want %s", line, got, fields[2])
+			continue
+		}
+
+		levels := p.lineLevels()
+		var gotLevels, gotOrder []string
+		for i, level := range levels {
+			if bidiRemoved(p.classes[i]) {
+				gotLevels = append(gotLevels, "x")
+			} else {
+				gotLevels = append(gotLevels, strconv.Itoa(int(level)))
+			}
+		}
+		for _, i := range bidiReorder(levels) {
+			if !bidiRemoved(p.classes[i]) {
+				gotOrder = append(gotOrder, strconv.Itoa(i))
+			}
+		}
+		if got := strings.Join(gotLevels, " "); got != strings.TrimSpace(fields[3]) {
+			t.Errorf("line %d: levels %s, want %s", line, got, fields[3])
+		}
+		if got := strings.Join(gotOrder, " "); got != strings.TrimSpace(fields[4]) {
+			t.Errorf("line %d: order %s, want %s", line, got, fields[4])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,253 @@
+package utilities
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CaseStyle is a way of writing identifiers made of several words
+type CaseStyle int
+
+const (
+	// CaseSnake is lowercase words joined by underscores, "http_server"
+	CaseSnake CaseStyle = iota
+	// CaseKebab is lowercase words joined by hyphens, "http-server"
+	CaseKebab
+	// CaseCamel starts every word but the first with a capital, "httpServer"
+	CaseCamel
+	// CasePascal starts every word with a capital, "HTTPServer"
+	CasePascal
+	// CaseTitle is capitalized words joined by spaces, "HTTP Server"
+	CaseTitle
+)
+
+// CaseOptions changes how identifiers are split into words and put back together
+type CaseOptions struct {
+	// Initialisms are words that keep their own spelling in CaseCamel,
+	// CasePascal and CaseTitle, such as "ID" or "IPv4". Where one of them is
+	// written in the input, followed by anything but a lowercase letter, it is
+	// always a word of its own, so "JSONAPI" splits into "JSON" and "API"
+	Initialisms []string
+}
+
+// DefaultInitialisms are the initialisms Go code spells in capitals
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP",
+	"HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA",
+	"SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID",
+	"URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// DefaultCaseOptions are used by ToSnake, ToKebab, ToCamel, ToPascal and
+// ToTitle, they know about DefaultInitialisms
+var DefaultCaseOptions = CaseOptions{Initialisms: DefaultInitialisms}
+
+// ToSnake writes s in snake_case
+/*
+utilities.ToSnake("HTTPServer") == "http_server"
+*/
+func ToSnake(s string) string {
+	return ConvertCase(s, CaseSnake, DefaultCaseOptions)
+}
+
+// ToKebab writes s in kebab-case, as used for command line flags
+/*
+utilities.ToKebab("maxRetryCount") == "max-retry-count"
+*/
+func ToKebab(s string) string {
+	return ConvertCase(s, CaseKebab, DefaultCaseOptions)
+}
+
+// ToCamel writes s in camelCase, as used for JSON keys
+/*
+utilities.ToCamel("user_id") == "userID"
+*/
+func ToCamel(s string) string {
+	return ConvertCase(s, CaseCamel, DefaultCaseOptions)
+}
+
+// ToPascal writes s in PascalCase, as used for exported Go names
+/*
+utilities.ToPascal("http-server") == "HTTPServer"
+*/
+func ToPascal(s string) string {
+	return ConvertCase(s, CasePascal, DefaultCaseOptions)
+}
+
+// ToTitle writes s as capitalized words separated by spaces
+/*
+utilities.ToTitle("userAPIKey") == "User API Key"
+*/
+func ToTitle(s string) string {
+	return ConvertCase(s, CaseTitle, DefaultCaseOptions)
+}
+
+// ConvertCase splits s into words and writes them in style. Words end at every
+// character that is not a letter, digit or mark, where a lowercase letter is
+// followed by an uppercase one, before the last capital of a run of capitals
+// that goes on in lowercase, and between letters with and without case such as
+// Latin and Han. Digits stay with the word in front of them, "Base64Encode" is
+// "base64" and "Encode". Letters are lowered and capitalized with the Unicode
+// case mappings, so Greek and Cyrillic work the same as Latin, and letters
+// without case are left as they are
+/*
+utilities.ConvertCase("XMLHttpRequest", utilities.CaseSnake, utilities.CaseOptions{}) == "xml_http_request"
+*/
+func ConvertCase(s string, style CaseStyle, opts CaseOptions) string {
+	words := splitWords(s, opts.Initialisms)
+	var b strings.Builder
+	for i, word := range words {
+		switch style {
+		case CaseSnake, CaseKebab:
+			if i > 0 {
+				if style == CaseSnake {
+					b.WriteByte('_')
+				} else {
+					b.WriteByte('-')
+				}
+			}
+			b.WriteString(strings.ToLower(word))
+		case CaseCamel:
+			if i == 0 {
+				b.WriteString(strings.ToLower(word))
+			} else {
+				b.WriteString(capitalize(word, opts.Initialisms))
+			}
+		case CasePascal:
+			b.WriteString(capitalize(word, opts.Initialisms))
+		case CaseTitle:
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(capitalize(word, opts.Initialisms))
+		}
+	}
+	return b.String()
+}
+
+// Splits s into the words of an identifier
+func splitWords(s string, initialisms []string) []string {
+	var words []string
+	chunks := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	for _, chunk := range chunks {
+		runes := []rune(chunk)
+		start := 0
+		for i := 0; i < len(runes); {
+			if i == start {
+				if n := matchInitialism(runes[i:], initialisms); n > 0 {
+					words = append(words, string(runes[i:i+n]))
+					i += n
+					start = i
+					continue
+				}
+			} else if wordBoundary(runes, i) {
+				words = append(words, string(runes[start:i]))
+				start = i
+				continue
+			}
+			i++
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
+
+// Reports whether a word starts at runes[i]. Marks belong to the letter in
+// front of them, so they are skipped when looking at the neighbours
+func wordBoundary(runes []rune, i int) bool {
+	cur := runes[i]
+	if unicode.IsMark(cur) {
+		return false
+	}
+	prev := rune(-1)
+	for j := i - 1; j >= 0; j-- {
+		if !unicode.IsMark(runes[j]) {
+			prev = runes[j]
+			break
+		}
+	}
+	next := rune(-1)
+	for j := i + 1; j < len(runes); j++ {
+		if !unicode.IsMark(runes[j]) {
+			next = runes[j]
+			break
+		}
+	}
+
+	switch {
+	case prev < 0:
+		return false
+	case isUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+		// fooBar and 64Encode
+		return true
+	case isUpper(cur) && isUpper(prev) && unicode.IsLower(next):
+		// HTTPServer
+		return true
+	case unicode.IsLetter(prev) && unicode.IsLetter(cur):
+		// 日本Language
+		return isCaseless(prev) != isCaseless(cur)
+	}
+	return false
+}
+
+// Reports whether r is an uppercase or titlecase letter such as U+01C5 ǅ
+func isUpper(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsTitle(r)
+}
+
+// Reports whether r is a letter of a script without case, such as Han or Arabic
+func isCaseless(r rune) bool {
+	return !unicode.IsUpper(r) && !unicode.IsLower(r) && !unicode.IsTitle(r)
+}
+
+// Returns the number of runes of the longest initialism that runes start with,
+// or 0. An initialism may be followed by an s for its plural, "IDs", but not by
+// a lowercase letter or a digit since then it is only the start of a longer word
+func matchInitialism(runes []rune, initialisms []string) int {
+	best := 0
+	for _, initialism := range initialisms {
+		n := utf8.RuneCountInString(initialism)
+		if n <= best || n > len(runes) || string(runes[:n]) != initialism {
+			continue
+		}
+		if n < len(runes) && runes[n] == 's' && (n+1 == len(runes) || !endsInitialism(runes[n+1])) {
+			n++
+		}
+		if n < len(runes) && endsInitialism(runes[n]) {
+			continue
+		}
+		best = n
+	}
+	return best
+}
+
+// Reports whether r carries on the word, so there can be no initialism in front of it
+func endsInitialism(r rune) bool {
+	return unicode.IsLower(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// Capitalizes word, an initialism takes the spelling from the list. Digits
+// after an initialism and a plural s keep it an initialism, "HTTP2" and "IDs"
+func capitalize(word string, initialisms []string) string {
+	core := strings.TrimRightFunc(word, unicode.IsDigit)
+	suffix := word[len(core):]
+	if suffix == "" && len(core) > 1 && core[len(core)-1] == 's' {
+		core, suffix = core[:len(core)-1], "s"
+	}
+	for _, initialism := range initialisms {
+		if strings.EqualFold(word, initialism) {
+			return initialism
+		}
+		if strings.EqualFold(core, initialism) {
+			return initialism + strings.ToLower(suffix)
+		}
+	}
+
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToTitle(r)) + strings.ToLower(word[size:])
+}
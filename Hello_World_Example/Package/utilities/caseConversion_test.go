@@ -0,0 +1,74 @@
+package utilities
+
+import "testing"
+
+func TestCaseConversion(t *testing.T) {
+	cases := []struct {
+		in                                 string
+		snake, kebab, camel, pascal, title string
+	}{
+		{"HTTPServer", "http_server", "http-server", "httpServer", "HTTPServer", "HTTP Server"},
+		{"user_id", "user_id", "user-id", "userID", "UserID", "User ID"},
+		{"max-retry-count", "max_retry_count", "max-retry-count", "maxRetryCount", "MaxRetryCount", "Max Retry Count"},
+		{"  Hello, world!  ", "hello_world", "hello-world", "helloWorld", "HelloWorld", "Hello World"},
+		{"JSONAPIResponse", "json_api_response", "json-api-response", "jsonAPIResponse", "JSONAPIResponse", "JSON API Response"},
+		{"userIDs", "user_ids", "user-ids", "userIDs", "UserIDs", "User IDs"},
+		{"Identity", "identity", "identity", "identity", "Identity", "Identity"},
+		// Digits stay with the word in front of them
+		{"Base64Encode", "base64_encode", "base64-encode", "base64Encode", "Base64Encode", "Base64 Encode"},
+		{"HTTP2Server", "http2_server", "http2-server", "http2Server", "HTTP2Server", "HTTP2 Server"},
+		{"utf8_reader", "utf8_reader", "utf8-reader", "utf8Reader", "UTF8Reader", "UTF8 Reader"},
+		{"version 2", "version_2", "version-2", "version2", "Version2", "Version 2"},
+		// Cyrillic and Greek have case, Han does not
+		{"ПриветМир", "привет_мир", "привет-мир", "приветМир", "ПриветМир", "Привет Мир"},
+		{"ΚΑΛΗ_ΜΕΡΑ", "καλη_μερα", "καλη-μερα", "καληΜερα", "ΚαληΜερα", "Καλη Μερα"},
+		{"日本Language", "日本_language", "日本-language", "日本Language", "日本Language", "日本 Language"},
+		{"caféBar", "café_bar", "café-bar", "caféBar", "CaféBar", "Café Bar"},
+		// The accent written as U+0301 stays on its e
+		{"cafe\u0301Bar", "cafe\u0301_bar", "cafe\u0301-bar", "cafe\u0301Bar", "Cafe\u0301Bar", "Cafe\u0301 Bar"},
+		{"", "", "", "", "", ""},
+	}
+
+	for _, c := range cases {
+		if got := ToSnake(c.in); got != c.snake {
+			t.Errorf("ToSnake(%q) == %q, want %q", c.in, got, c.snake)
+		}
+		if got := ToKebab(c.in); got != c.kebab {
+			t.Errorf("ToKebab(%q) == %q, want %q", c.in, got, c.kebab)
+		}
+		if got := ToCamel(c.in); got != c.camel {
+			t.Errorf("ToCamel(%q) == %q, want %q", c.in, got, c.camel)
+		}
+		if got := ToPascal(c.in); got != c.pascal {
+			t.Errorf("ToPascal(%q) == %q, want %q", c.in, got, c.pascal)
+		}
+		if got := ToTitle(c.in); got != c.title {
+			t.Errorf("ToTitle(%q) == %q, want %q", c.in, got, c.title)
+		}
+	}
+}
+
+func TestConvertCaseInitialisms(t *testing.T) {
+	cases := []struct {
+		in          string
+		style       CaseStyle
+		initialisms []string
+		want        string
+	}{
+		// Without initialisms a run of capitals is still one word
+		{"XMLHTTPRequest", CaseSnake, nil, "xmlhttp_request"},
+		{"XMLHTTPRequest", CaseSnake, []string{"XML", "HTTP"}, "xml_http_request"},
+		{"user_id", CasePascal, nil, "UserId"},
+		{"ipv4_address", CasePascal, []string{"IPv4"}, "IPv4Address"},
+		{"IPv4Address", CaseSnake, []string{"IPv4"}, "ipv4_address"},
+		{"oauth_token", CaseCamel, []string{"OAuth"}, "oauthToken"},
+		{"oauth_token", CaseTitle, []string{"OAuth"}, "OAuth Token"},
+	}
+
+	for _, c := range cases {
+		got := ConvertCase(c.in, c.style, CaseOptions{Initialisms: c.initialisms})
+		if got != c.want {
+			t.Errorf("ConvertCase(%q, %d, %q) == %q, want %q", c.in, c.style, c.initialisms, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,508 @@
+package utilities
+
+import (
+	"math"
+	"slices"
+)
+
+// Edit distances and similarity measures between strings. They all work on
+// runes, so "世界" is two characters and not six bytes, and they all return
+// the steps that turn one string into the other along with the score
+/*
+result := utilities.Levenshtein("kitten", "sitting", utilities.EditOptions{})
+result.Distance == 3
+*/
+
+// EditKind is the kind of a single step of an edit script
+type EditKind int
+
+const (
+	// EditKeep leaves a rune of a as it is, it is the same rune in b
+	EditKeep EditKind = iota
+	// EditInsert inserts a rune of b
+	EditInsert
+	// EditDelete deletes a rune of a
+	EditDelete
+	// EditReplace replaces a rune of a with a rune of b
+	EditReplace
+	// EditTranspose swaps two runes of a, see EditOp for where the second one is
+	EditTranspose
+)
+
+func (k EditKind) String() string {
+	switch k {
+	case EditKeep:
+		return "keep"
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditReplace:
+		return "replace"
+	case EditTranspose:
+		return "transpose"
+	}
+	return "unknown"
+}
+
+// EditOp is a single step of an edit script. A and B are the rune offsets in
+// the two strings the step works on. An EditTranspose turns a[A] and a later
+// rune of a into b[B] and a later rune of b. Usually they are the runes right
+// after, but DamerauLevenshtein may delete runes of a and insert runes of b
+// between the two halves, those EditDelete and EditInsert steps come right
+// after the EditTranspose in the script
+type EditOp struct {
+	Kind EditKind
+	A, B int
+}
+
+// EditOptions changes how the edit distances are worked out
+type EditOptions struct {
+	// MaxDistance stops the comparison as soon as the distance is known to be
+	// larger. Only the cells of the table at most MaxDistance from its diagonal
+	// are worked out, so the time and memory grow with MaxDistance times the
+	// length instead of with the product of the lengths. It only counts when
+	// Bounded is true, so a MaxDistance of 0 asks whether the strings are equal
+	MaxDistance int
+	// Bounded turns on MaxDistance
+	Bounded bool
+	// MinSimilarity does the same for JaroWinkler, it stops as soon as the
+	// similarity is known to be lower. Zero means there is no limit
+	MinSimilarity float64
+}
+
+// EditResult is the outcome of an edit distance
+type EditResult struct {
+	// Distance is the number of edits needed to turn a into b
+	Distance int
+	// Script lists the steps from the start of the strings to their end
+	Script []EditOp
+	// Exceeded is true when the comparison stopped early because the distance
+	// is larger than MaxDistance. Distance is then MaxDistance+1 and Script is nil
+	Exceeded bool
+}
+
+// Holds a table of (len(a)+1) x (len(b)+1) costs, row i is the first i runes
+// of a. With a band only the cells at most band columns from the diagonal are
+// kept. A cell further out costs more than the band allows whatever the
+// strings are, it reads as editOutside and setting it does nothing
+type editMatrix struct {
+	rows, cols int
+	band       int // -1 when every cell is kept
+	width      int // The cells kept of every row
+	cost       []int
+}
+
+// The cost of a cell outside the band, larger than any real cost but far from
+// overflowing when a few edits are added to it
+const editOutside = math.MaxInt / 4
+
+func newEditMatrix(rows, cols, band int) *editMatrix {
+	m := &editMatrix{rows: rows, cols: cols, band: band, width: cols}
+	// A band as wide as the table is the whole table
+	if band < 0 || band >= max(rows, cols) {
+		m.band = -1
+	} else {
+		m.width = 2*band + 1
+	}
+	m.cost = make([]int, rows*m.width)
+	return m
+}
+
+// Returns where cell i, j is kept in cost, false when it is outside the band
+func (m *editMatrix) index(i, j int) (int, bool) {
+	if m.band < 0 {
+		return i*m.cols + j, true
+	}
+	k := j - i + m.band
+	if k < 0 || k >= m.width {
+		return 0, false
+	}
+	return i*m.width + k, true
+}
+
+func (m *editMatrix) at(i, j int) int {
+	if k, ok := m.index(i, j); ok {
+		return m.cost[k]
+	}
+	return editOutside
+}
+
+func (m *editMatrix) set(i, j, cost int) {
+	if k, ok := m.index(i, j); ok {
+		m.cost[k] = cost
+	}
+}
+
+// Returns the first and last column of row i that are inside the band
+func (m *editMatrix) span(i int) (int, int) {
+	if m.band < 0 {
+		return 0, m.cols - 1
+	}
+	return max(i-m.band, 0), min(i+m.band, m.cols-1)
+}
+
+// Reports whether every cell of row i from column from on is above the limit.
+// Costs never go down from one row to the next, so the final distance is above
+// it as well
+func (m *editMatrix) rowExceeds(i, from int, opts EditOptions) bool {
+	if !opts.Bounded {
+		return false
+	}
+	lo, hi := m.span(i)
+	for j := max(lo, from); j <= hi; j++ {
+		if m.at(i, j) <= opts.MaxDistance {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the band of cells the options need worked out, -1 for all of them
+func (o EditOptions) band() int {
+	if !o.Bounded {
+		return -1
+	}
+	return max(o.MaxDistance, 0)
+}
+
+// Reports whether distance is over the limit of the options
+func (o EditOptions) over(distance int) bool {
+	return o.Bounded && distance > o.MaxDistance
+}
+
+// Returns the result for a comparison that stopped early
+func exceeded(opts EditOptions) EditResult {
+	return EditResult{Distance: opts.MaxDistance + 1, Exceeded: true}
+}
+
+// Levenshtein returns the number of single rune insertions, deletions and
+// replacements needed to turn a into b together with those steps
+/*
+utilities.Levenshtein("Hello, 世界", "Hello, 世间", utilities.EditOptions{}).Distance == 1
+*/
+func Levenshtein(a, b string, opts EditOptions) EditResult {
+	ra, rb := []rune(a), []rune(b)
+	n, m := len(ra), len(rb)
+	// Every rune of the length difference needs an insertion or a deletion
+	if opts.over(abs(n - m)) {
+		return exceeded(opts)
+	}
+
+	d := newEditMatrix(n+1, m+1, opts.band())
+	for j := 0; j <= m; j++ {
+		d.set(0, j, j)
+	}
+	for i := 1; i <= n; i++ {
+		d.set(i, 0, i)
+		lo, hi := d.span(i)
+		for j := max(lo, 1); j <= hi; j++ {
+			replace := d.at(i-1, j-1)
+			if ra[i-1] != rb[j-1] {
+				replace++
+			}
+			d.set(i, j, min(replace, d.at(i-1, j)+1, d.at(i, j-1)+1))
+		}
+		if d.rowExceeds(i, 0, opts) {
+			return exceeded(opts)
+		}
+	}
+	if opts.over(d.at(n, m)) {
+		return exceeded(opts)
+	}
+
+	// Walk back from the end, taking any step that explains the cost
+	var script []EditOp
+	for i, j := n, m; i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && ra[i-1] == rb[j-1] && d.at(i, j) == d.at(i-1, j-1):
+			i, j = i-1, j-1
+			script = append(script, EditOp{EditKeep, i, j})
+		case i > 0 && j > 0 && d.at(i, j) == d.at(i-1, j-1)+1:
+			i, j = i-1, j-1
+			script = append(script, EditOp{EditReplace, i, j})
+		case i > 0 && d.at(i, j) == d.at(i-1, j)+1:
+			i--
+			script = append(script, EditOp{EditDelete, i, j})
+		default:
+			j--
+			script = append(script, EditOp{EditInsert, i, j})
+		}
+	}
+	slices.Reverse(script)
+	return EditResult{Distance: d.at(n, m), Script: script}
+}
+
+// DamerauLevenshtein is Levenshtein that also counts swapping two runes as a
+// single edit. This is the unrestricted distance of Lowrance and Wagner, so
+// runes may still be edited after they were swapped: "ca" and "abc" are two
+// edits apart, not three
+/*
+utilities.DamerauLevenshtein("abcd", "acbd", utilities.EditOptions{}).Distance == 1
+*/
+func DamerauLevenshtein(a, b string, opts EditOptions) EditResult {
+	ra, rb := []rune(a), []rune(b)
+	n, m := len(ra), len(rb)
+	if opts.over(abs(n - m)) {
+		return exceeded(opts)
+	}
+
+	// The table has an extra row and column in front holding a cost no path
+	// can reach, so row and column 1 are the empty prefixes
+	worst := n + m
+	d := newEditMatrix(n+2, m+2, opts.band())
+	// Where the best path into every cell came from, the step is the kind of
+	// edit and the row and column are those of the cell before it. It is kept
+	// for the same cells as d
+	type origin struct {
+		kind EditKind
+		i, j int
+	}
+	from := make([]origin, len(d.cost))
+	setFrom := func(i, j int, o origin) {
+		if k, ok := d.index(i, j); ok {
+			from[k] = o
+		}
+	}
+	d.set(0, 0, worst)
+	for i := 0; i <= n; i++ {
+		d.set(i+1, 0, worst)
+		d.set(i+1, 1, i)
+		setFrom(i+1, 1, origin{EditDelete, i, 1})
+	}
+	for j := 0; j <= m; j++ {
+		d.set(0, j+1, worst)
+		d.set(1, j+1, j)
+		setFrom(1, j+1, origin{EditInsert, 1, j})
+	}
+
+	// The last row each rune of a was seen in
+	lastRow := map[rune]int{}
+	for i := 1; i <= n; i++ {
+		// The last column of this row where the runes matched. A match left of
+		// the band is never used, a swap with it would cost more than the band
+		// allows
+		lastCol := 0
+		lo, hi := d.span(i + 1)
+		for j := max(lo-1, 1); j <= min(hi-1, m); j++ {
+			i1, j1 := lastRow[rb[j-1]], lastCol
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+				lastCol = j
+			}
+			best := origin{EditReplace, i, j}
+			if cost == 0 {
+				best.kind = EditKeep
+			}
+			value := d.at(i, j) + cost
+			if v := d.at(i, j+1) + 1; v < value {
+				value, best = v, origin{EditDelete, i, j + 1}
+			}
+			if v := d.at(i+1, j) + 1; v < value {
+				value, best = v, origin{EditInsert, i + 1, j}
+			}
+			if v := d.at(i1, j1) + (i - i1 - 1) + 1 + (j - j1 - 1); v < value {
+				value, best = v, origin{EditTranspose, i1, j1}
+			}
+			d.set(i+1, j+1, value)
+			setFrom(i+1, j+1, best)
+		}
+		lastRow[ra[i-1]] = i
+		if d.rowExceeds(i+1, 1, opts) {
+			return exceeded(opts)
+		}
+	}
+	distance := d.at(n+1, m+1)
+	if opts.over(distance) {
+		return exceeded(opts)
+	}
+
+	// Every step is collected back to front, a swap is written out as its own
+	// step followed by the runes deleted and inserted between its halves
+	var script []EditOp
+	for i, j := n+1, m+1; i > 1 || j > 1; {
+		k, _ := d.index(i, j)
+		o := from[k]
+		switch o.kind {
+		case EditTranspose:
+			for k := j - 2; k > o.j; k-- {
+				script = append(script, EditOp{EditInsert, i - 1, k - 1})
+			}
+			for k := i - 2; k > o.i; k-- {
+				script = append(script, EditOp{EditDelete, k - 1, o.j})
+			}
+			script = append(script, EditOp{EditTranspose, o.i - 1, o.j - 1})
+			i, j = o.i, o.j
+		default:
+			script = append(script, EditOp{o.kind, o.i - 1, o.j - 1})
+			i, j = o.i, o.j
+		}
+	}
+	slices.Reverse(script)
+	return EditResult{Distance: distance, Script: script}
+}
+
+// LongestCommonSubsequence returns the longest run of runes that appears in
+// both a and b in the same order, though not necessarily next to each other.
+// The result counts the insertions and deletions that turn a into b, which is
+// len(a)+len(b)-2*len(subsequence) in runes, the script is the matching diff
+/*
+lcs, result := utilities.LongestCommonSubsequence("Hello, 世界", "Yellow 世", utilities.EditOptions{})
+lcs == "ello 世"
+*/
+func LongestCommonSubsequence(a, b string, opts EditOptions) (string, EditResult) {
+	ra, rb := []rune(a), []rune(b)
+	n, m := len(ra), len(rb)
+	if opts.over(abs(n - m)) {
+		return "", exceeded(opts)
+	}
+
+	d := newEditMatrix(n+1, m+1, opts.band())
+	for j := 0; j <= m; j++ {
+		d.set(0, j, j)
+	}
+	for i := 1; i <= n; i++ {
+		d.set(i, 0, i)
+		lo, hi := d.span(i)
+		for j := max(lo, 1); j <= hi; j++ {
+			cost := min(d.at(i-1, j), d.at(i, j-1)) + 1
+			if ra[i-1] == rb[j-1] {
+				cost = min(cost, d.at(i-1, j-1))
+			}
+			d.set(i, j, cost)
+		}
+		if d.rowExceeds(i, 0, opts) {
+			return "", exceeded(opts)
+		}
+	}
+	if opts.over(d.at(n, m)) {
+		return "", exceeded(opts)
+	}
+
+	var script []EditOp
+	var common []rune
+	for i, j := n, m; i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && ra[i-1] == rb[j-1] && d.at(i, j) == d.at(i-1, j-1):
+			i, j = i-1, j-1
+			script = append(script, EditOp{EditKeep, i, j})
+			common = append(common, ra[i])
+		case i > 0 && d.at(i, j) == d.at(i-1, j)+1:
+			i--
+			script = append(script, EditOp{EditDelete, i, j})
+		default:
+			j--
+			script = append(script, EditOp{EditInsert, i, j})
+		}
+	}
+	slices.Reverse(script)
+	slices.Reverse(common)
+	return string(common), EditResult{Distance: d.at(n, m), Script: script}
+}
+
+// JaroResult is the outcome of JaroWinkler
+type JaroResult struct {
+	// Similarity goes from 0 for nothing in common to 1 for equal strings
+	Similarity float64
+	// Matches pairs the matching runes of a with those of b in order. A pair
+	// is EditKeep when the runes are the same and EditTranspose when the
+	// matching runes are in a different order in b
+	Matches []EditOp
+	// Below is true when the comparison stopped early because the similarity
+	// is lower than MinSimilarity. Similarity is then 0 and Matches is nil
+	Below bool
+}
+
+// The Winkler prefix bonus, up to four runes each worth a tenth of what is missing
+const (
+	winklerPrefix = 4
+	winklerScale  = 0.1
+)
+
+// JaroWinkler returns the Jaro-Winkler similarity of a and b. It suits short
+// strings such as names, where typos are common and the start matters most
+/*
+utilities.JaroWinkler("MARTHA", "MARHTA", utilities.EditOptions{}).Similarity ≈ 0.961
+*/
+func JaroWinkler(a, b string, opts EditOptions) JaroResult {
+	ra, rb := []rune(a), []rune(b)
+	n, m := len(ra), len(rb)
+	if n == 0 && m == 0 {
+		return JaroResult{Similarity: 1}
+	}
+
+	prefix := 0
+	for prefix < min(n, m, winklerPrefix) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	similarity := func(matches, transpositions int) float64 {
+		if matches == 0 {
+			return 0
+		}
+		mf := float64(matches)
+		jaro := (mf/float64(n) + mf/float64(m) + (mf-float64(transpositions))/mf) / 3
+		return jaro + float64(prefix)*winklerScale*(1-jaro)
+	}
+	below := func(bound float64) bool {
+		return opts.MinSimilarity > 0 && bound < opts.MinSimilarity
+	}
+
+	// Even if every rune of the shorter string matched
+	if below(similarity(min(n, m), 0)) {
+		return JaroResult{Below: true}
+	}
+
+	// Runes match when they are equal and no further apart than the window
+	window := max(max(n, m)/2-1, 0)
+	matchedA := make([]int, n) // The matching offset in b, or -1
+	matchedB := make([]bool, m)
+	matches := 0
+	for i := range ra {
+		matchedA[i] = -1
+		for j := max(0, i-window); j < min(m, i+window+1); j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i] = j
+				matchedB[j] = true
+				matches++
+				break
+			}
+		}
+	}
+	if below(similarity(matches, 0)) {
+		return JaroResult{Below: true}
+	}
+
+	// The k-th match of a is paired with the k-th match of b, half of the
+	// pairs that differ count as transpositions
+	var pairs []EditOp
+	j := 0
+	differ := 0
+	for i, mj := range matchedA {
+		if mj < 0 {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		kind := EditKeep
+		if ra[i] != rb[j] {
+			kind = EditTranspose
+			differ++
+		}
+		pairs = append(pairs, EditOp{kind, i, j})
+		j++
+	}
+	result := similarity(matches, differ/2)
+	if below(result) {
+		return JaroResult{Below: true}
+	}
+	return JaroResult{Similarity: result, Matches: pairs}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
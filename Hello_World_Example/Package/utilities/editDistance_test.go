@@ -0,0 +1,245 @@
+package utilities
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Follows script to build b out of a, so every test can check that the script
+// really turns one string into the other and costs what the distance says
+func applyScript(t *testing.T, a, b string, script []EditOp) (string, int) {
+	t.Helper()
+	ra, rb := []rune(a), []rune(b)
+	var out []rune
+	cost := 0
+	var pending []rune // Second half of a swap, written after its inserts
+	for k, op := range script {
+		switch op.Kind {
+		case EditKeep:
+			out = append(out, ra[op.A])
+		case EditReplace:
+			out = append(out, rb[op.B])
+			cost++
+		case EditInsert:
+			out = append(out, rb[op.B])
+			cost++
+		case EditDelete:
+			cost++
+		case EditTranspose:
+			deleted := 0
+			for _, next := range script[k+1:] {
+				if next.Kind != EditDelete {
+					break
+				}
+				deleted++
+			}
+			out = append(out, ra[op.A+1+deleted])
+			pending = append(pending, ra[op.A])
+			cost++
+		}
+		// The swap is finished once its inserts are done
+		if len(pending) > 0 && (k+1 == len(script) || (script[k+1].Kind != EditInsert && script[k+1].Kind != EditDelete)) {
+			out = append(out, pending...)
+			pending = nil
+		}
+	}
+	return string(out), cost
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"Hello, 世界", "Hello, 世界", 0},
+		{"Hello, 世界", "Hello, 世间", 1},
+		{"世界", "界世", 2},
+		{"flaw", "lawn", 2},
+	}
+
+	for _, c := range cases {
+		got := Levenshtein(c.a, c.b, EditOptions{})
+		if got.Distance != c.want {
+			t.Errorf("Levenshtein(%q, %q) == %d, want %d", c.a, c.b, got.Distance, c.want)
+		}
+		if out, cost := applyScript(t, c.a, c.b, got.Script); out != c.b || cost != c.want {
+			t.Errorf("Levenshtein(%q, %q) script gives %q for %d edits", c.a, c.b, out, cost)
+		}
+	}
+}
+
+func TestDamerauLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abcd", "acbd", 1},
+		{"世界", "界世", 1},
+		// A swap followed by an insert between the two halves
+		{"ca", "abc", 2},
+		{"abc", "ca", 2},
+		{"a cat", "an act", 2},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, c := range cases {
+		got := DamerauLevenshtein(c.a, c.b, EditOptions{})
+		if got.Distance != c.want {
+			t.Errorf("DamerauLevenshtein(%q, %q) == %d, want %d", c.a, c.b, got.Distance, c.want)
+		}
+		if out, cost := applyScript(t, c.a, c.b, got.Script); out != c.b || cost != c.want {
+			t.Errorf("DamerauLevenshtein(%q, %q) script %v gives %q for %d edits", c.a, c.b, got.Script, out, cost)
+		}
+	}
+}
+
+func TestLongestCommonSubsequence(t *testing.T) {
+	cases := []struct {
+		a, b, want string
+	}{
+		{"", "", ""},
+		{"abc", "", ""},
+		{"ABCBDAB", "BDCABA", "BCBA"},
+		{"Hello, 世界", "Yellow 世", "ello 世"},
+	}
+
+	for _, c := range cases {
+		got, result := LongestCommonSubsequence(c.a, c.b, EditOptions{})
+		if got != c.want {
+			t.Errorf("LongestCommonSubsequence(%q, %q) == %q, want %q", c.a, c.b, got, c.want)
+		}
+		distance := len([]rune(c.a)) + len([]rune(c.b)) - 2*len([]rune(c.want))
+		if out, cost := applyScript(t, c.a, c.b, result.Script); out != c.b || cost != distance || result.Distance != distance {
+			t.Errorf("LongestCommonSubsequence(%q, %q) script gives %q for %d edits, distance %d", c.a, c.b, out, cost, result.Distance)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "", 0},
+		{"MARTHA", "MARHTA", 0.9611},
+		{"DWAYNE", "DUANE", 0.84},
+		{"DIXON", "DICKSONX", 0.8133},
+		{"Hello, 世界", "Hello, 世界", 1},
+		{"abc", "xyz", 0},
+	}
+
+	for _, c := range cases {
+		got := JaroWinkler(c.a, c.b, EditOptions{})
+		if math.Abs(got.Similarity-c.want) > 0.0001 {
+			t.Errorf("JaroWinkler(%q, %q) == %.4f, want %.4f", c.a, c.b, got.Similarity, c.want)
+		}
+	}
+
+	// MARTHA and MARHTA swap the T and the H
+	got := JaroWinkler("MARTHA", "MARHTA", EditOptions{})
+	transposed := 0
+	for _, op := range got.Matches {
+		if op.Kind == EditTranspose {
+			transposed++
+		}
+	}
+	if len(got.Matches) != 6 || transposed != 2 {
+		t.Errorf("JaroWinkler matches == %v, want 6 with 2 transposed", got.Matches)
+	}
+}
+
+// The limits stop early and say so, but never change a result within them
+func TestEditLimits(t *testing.T) {
+	a, b := "Hello, 世界", "Goodbye, moon"
+	limit := EditOptions{MaxDistance: 3, Bounded: true}
+	for name, result := range map[string]EditResult{
+		"Levenshtein":        Levenshtein(a, b, limit),
+		"DamerauLevenshtein": DamerauLevenshtein(a, b, limit),
+	} {
+		if !result.Exceeded || result.Distance != 4 || result.Script != nil {
+			t.Errorf("%s with MaxDistance 3 == %+v, want exceeded", name, result)
+		}
+	}
+	if _, result := LongestCommonSubsequence(a, b, limit); !result.Exceeded {
+		t.Errorf("LongestCommonSubsequence with MaxDistance 3 == %+v, want exceeded", result)
+	}
+	if result := JaroWinkler(a, b, EditOptions{MinSimilarity: 0.9}); !result.Below {
+		t.Errorf("JaroWinkler with MinSimilarity 0.9 == %+v, want below", result)
+	}
+
+	if result := Levenshtein("kitten", "sitting", limit); result.Exceeded || result.Distance != 3 {
+		t.Errorf("Levenshtein with MaxDistance 3 == %+v, want 3", result)
+	}
+	if result := JaroWinkler("MARTHA", "MARHTA", EditOptions{MinSimilarity: 0.9}); result.Below {
+		t.Errorf("JaroWinkler with MinSimilarity 0.9 == %+v, want 0.96", result)
+	}
+
+	// A limit of 0 asks whether the strings are equal
+	equal := EditOptions{Bounded: true}
+	if result := Levenshtein("世界", "世界", equal); result.Exceeded || result.Distance != 0 || len(result.Script) != 2 {
+		t.Errorf("Levenshtein of equal strings with MaxDistance 0 == %+v", result)
+	}
+	if result := DamerauLevenshtein("世界", "界世", equal); !result.Exceeded || result.Distance != 1 {
+		t.Errorf("DamerauLevenshtein with MaxDistance 0 == %+v, want exceeded", result)
+	}
+}
+
+// Working out only the band around the diagonal gives the same results as the
+// whole table for every distance within the limit
+func TestEditBand(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	word := func() string {
+		r := make([]rune, rng.Intn(12))
+		for i := range r {
+			r[i] = []rune("abc世")[rng.Intn(4)]
+		}
+		return string(r)
+	}
+	funcs := map[string]func(a, b string, opts EditOptions) EditResult{
+		"Levenshtein":        Levenshtein,
+		"DamerauLevenshtein": DamerauLevenshtein,
+		"LongestCommonSubsequence": func(a, b string, opts EditOptions) EditResult {
+			_, result := LongestCommonSubsequence(a, b, opts)
+			return result
+		},
+	}
+	for range 500 {
+		a, b := word(), word()
+		for name, f := range funcs {
+			want := f(a, b, EditOptions{})
+			for limit := 0; limit <= 6; limit++ {
+				got := f(a, b, EditOptions{MaxDistance: limit, Bounded: true})
+				if want.Distance <= limit {
+					if got.Exceeded || got.Distance != want.Distance {
+						t.Fatalf("%s(%q, %q) with MaxDistance %d == %+v, want %d", name, a, b, limit, got, want.Distance)
+					}
+					if out, cost := applyScript(t, a, b, got.Script); out != b || cost != got.Distance {
+						t.Fatalf("%s(%q, %q) with MaxDistance %d script gives %q for %d edits", name, a, b, limit, out, cost)
+					}
+				} else if !got.Exceeded || got.Distance != limit+1 {
+					t.Fatalf("%s(%q, %q) with MaxDistance %d == %+v, want exceeded", name, a, b, limit, got)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkLevenshtein(b *testing.B) {
+	x, y := "The quick brown fox jumps over the lazy dog", "Pack my box with five dozen liquor jugs"
+	b.Run("Unbounded", func(b *testing.B) {
+		for range b.N {
+			Levenshtein(x, y, EditOptions{})
+		}
+	})
+	b.Run("MaxDistance", func(b *testing.B) {
+		for range b.N {
+			Levenshtein(x, y, EditOptions{MaxDistance: 3, Bounded: true})
+		}
+	})
+}
@@ -0,0 +1,145 @@
+//go:build ignore
+
+// This program generates bidiTables.go, the Bidi_Class of every code point and
+// the bracket pairs of UAX #9, from UnicodeData.txt, DerivedBidiClass.txt,
+// DerivedCoreProperties.txt and BidiBrackets.txt. Package ucd has the flags it
+// takes
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities/internal/ucd"
+)
+
+// The short names of the Bidi_Class values by their long names, which the
+// @missing lines of DerivedBidiClass.txt use
+var shortClassNames = map[string]string{
+	"Left_To_Right":           "L",
+	"Right_To_Left":           "R",
+	"Arabic_Letter":           "AL",
+	"European_Number":         "EN",
+	"European_Separator":      "ES",
+	"European_Terminator":     "ET",
+	"Arabic_Number":           "AN",
+	"Common_Separator":        "CS",
+	"Nonspacing_Mark":         "NSM",
+	"Boundary_Neutral":        "BN",
+	"Paragraph_Separator":     "B",
+	"Segment_Separator":       "S",
+	"White_Space":             "WS",
+	"Other_Neutral":           "ON",
+	"Left_To_Right_Embedding": "LRE",
+	"Left_To_Right_Override":  "LRO",
+	"Right_To_Left_Embedding": "RLE",
+	"Right_To_Left_Override":  "RLO",
+	"Pop_Directional_Format":  "PDF",
+	"Left_To_Right_Isolate":   "LRI",
+	"Right_To_Left_Isolate":   "RLI",
+	"First_Strong_Isolate":    "FSI",
+	"Pop_Directional_Isolate": "PDI",
+}
+
+// Returns the short name of a Bidi_Class value given by its long or short name
+func shortClass(name string) string {
+	if short, ok := shortClassNames[name]; ok {
+		return short
+	}
+	for _, short := range shortClassNames {
+		if short == name {
+			return name
+		}
+	}
+	log.Fatalf("unknown Bidi_Class %q", name)
+	return ""
+}
+
+func main() {
+	flag.Parse()
+	// UnicodeData.txt does not say its version, the other files must agree
+	version := ucd.SameVersion("extracted/DerivedBidiClass.txt", "DerivedCoreProperties.txt", "BidiBrackets.txt")
+
+	// The defaults of the code points UnicodeData.txt does not list go in
+	// first, such as R in the Hebrew block, and the classes of UnicodeData.txt
+	// are written over them. Default ignorable code points and noncharacters
+	// that are not assigned yet are BN
+	values := make([]string, 0x110000)
+	for _, r := range ucd.Missing("extracted/DerivedBidiClass.txt") {
+		class := shortClass(r.Value)
+		for c := r.Lo; c <= r.Hi; c++ {
+			values[c] = class
+		}
+	}
+	for _, r := range ucd.Ranges("DerivedCoreProperties.txt", func(fields []string) string {
+		if fields[1] == "Default_Ignorable_Code_Point" {
+			return "BN"
+		}
+		return ""
+	}) {
+		for c := r.Lo; c <= r.Hi; c++ {
+			values[c] = "BN"
+		}
+	}
+	for c := rune(0xFDD0); c <= 0xFDEF; c++ {
+		values[c] = "BN"
+	}
+	for plane := rune(0); plane <= 0x10; plane++ {
+		values[plane<<16|0xFFFE] = "BN"
+		values[plane<<16|0xFFFF] = "BN"
+	}
+	ucd.UnicodeData(func(lo, hi rune, fields []string) {
+		for c := lo; c <= hi; c++ {
+			values[c] = fields[4]
+		}
+	})
+
+	// L is the default class, so it does not need to be stored
+	var classes []ucd.Range
+	for c, v := range values {
+		if v != "" && v != "L" {
+			classes = append(classes, ucd.Range{Lo: rune(c), Hi: rune(c), Value: v})
+		}
+	}
+	classes = ucd.Merge(classes)
+
+	type bracket struct {
+		r, pair rune
+		open    bool
+	}
+	var brackets []bracket
+	ucd.Fields("BidiBrackets.txt", func(fields []string) {
+		r, _, err := ucd.CodePoints(fields[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		pair, _, err := ucd.CodePoints(fields[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		brackets = append(brackets, bracket{r, pair, fields[2] == "o"})
+	})
+	sort.Slice(brackets, func(i, j int) bool { return brackets[i].r < brackets[j].r })
+
+	var buf bytes.Buffer
+	buf.WriteString(ucd.Header("genBidiTables.go"))
+	fmt.Fprintf(&buf, "// The version of the Unicode Character Database the tables were generated from\n")
+	fmt.Fprintf(&buf, "const bidiUnicodeVersion = %q\n\n", version)
+	fmt.Fprintf(&buf, "// Bidi_Class values other than L sorted by code point\n")
+	fmt.Fprintf(&buf, "var bidiClassTable = [...]bidiRange{\n")
+	for _, r := range classes {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, bidi%s},\n", r.Lo, r.Hi, r.Value)
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "// Bidi_Paired_Bracket and Bidi_Paired_Bracket_Type values sorted by code point\n")
+	fmt.Fprintf(&buf, "var bidiBracketTable = [...]bidiBracket{\n")
+	for _, b := range brackets {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, %v},\n", b.r, b.pair, b.open)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	ucd.WriteGo("bidiTables.go", buf.Bytes())
+}
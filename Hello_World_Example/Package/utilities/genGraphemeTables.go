@@ -0,0 +1,68 @@
+//go:build ignore
+
+// This program generates graphemeTables.go, the Grapheme_Cluster_Break values
+// with Extended_Pictographic and Indic_Conjunct_Break folded in, from
+// GraphemeBreakProperty.txt, emoji-data.txt and DerivedCoreProperties.txt.
+// Package ucd has the flags it takes
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities/internal/ucd"
+)
+
+func main() {
+	flag.Parse()
+
+	breaks := ucd.Ranges("auxiliary/GraphemeBreakProperty.txt", func(fields []string) string {
+		return fields[1]
+	})
+	pictographic := ucd.Ranges("emoji/emoji-data.txt", func(fields []string) string {
+		if fields[1] == "Extended_Pictographic" {
+			return "ExtendedPictographic"
+		}
+		return ""
+	})
+	conjuncts := ucd.Ranges("DerivedCoreProperties.txt", func(fields []string) string {
+		if fields[1] == "InCB" && len(fields) > 2 {
+			return fields[2]
+		}
+		return ""
+	})
+
+	// Extended_Pictographic is folded into the break property since none of
+	// the pictographs have a Grapheme_Cluster_Break value of their own
+	for _, p := range pictographic {
+		for _, b := range breaks {
+			if p.Lo <= b.Hi && b.Lo <= p.Hi {
+				log.Fatalf("Extended_Pictographic %04X..%04X overlaps %s", p.Lo, p.Hi, b.Value)
+			}
+		}
+	}
+	breaks = ucd.Merge(append(breaks, pictographic...))
+	conjuncts = ucd.Merge(conjuncts)
+
+	var buf bytes.Buffer
+	buf.WriteString(ucd.Header("genGraphemeTables.go"))
+	fmt.Fprintf(&buf, "// The version of the Unicode Character Database the tables were generated from\n")
+	fmt.Fprintf(&buf, "const graphemeUnicodeVersion = %q\n\n", *ucd.Version)
+	fmt.Fprintf(&buf, "// Grapheme_Cluster_Break and Extended_Pictographic values sorted by code point\n")
+	fmt.Fprintf(&buf, "var graphemeBreakTable = [...]graphemeRange{\n")
+	for _, r := range breaks {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, gb%s},\n", r.Lo, r.Hi, strings.ReplaceAll(r.Value, "_", ""))
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "// Indic_Conjunct_Break values sorted by code point\n")
+	fmt.Fprintf(&buf, "var conjunctBreakTable = [...]conjunctRange{\n")
+	for _, r := range conjuncts {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, incb%s},\n", r.Lo, r.Hi, r.Value)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	ucd.WriteGo("graphemeTables.go", buf.Bytes())
+}
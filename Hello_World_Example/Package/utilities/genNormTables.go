@@ -0,0 +1,187 @@
+//go:build ignore
+
+// This program generates normTables.go, the combining classes, decompositions
+// and compositions of the normalization forms, from UnicodeData.txt,
+// CompositionExclusions.txt and DerivedNormalizationProps.txt. Package ucd has
+// the flags it takes
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities/internal/ucd"
+)
+
+// A single step mapping from field 5 of UnicodeData.txt
+type mapping struct {
+	runes  []rune
+	compat bool // The mapping had a <tag> in front of it
+}
+
+func main() {
+	flag.Parse()
+
+	classes := ucd.Merge(ucd.Ranges("UnicodeData.txt", func(fields []string) string {
+		if fields[3] == "0" {
+			return ""
+		}
+		return fields[3]
+	}))
+
+	mappings := map[rune]mapping{}
+	ucd.Fields("UnicodeData.txt", func(fields []string) {
+		if fields[5] == "" {
+			return
+		}
+		r, _, err := ucd.CodePoints(fields[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		m := mapping{}
+		value := fields[5]
+		if strings.HasPrefix(value, "<") {
+			_, value, _ = strings.Cut(value, ">")
+			m.compat = true
+		}
+		if m.runes, err = ucd.Runes(value); err != nil {
+			log.Fatal(err)
+		}
+		mappings[r] = m
+	})
+
+	// Full_Composition_Exclusion is made up of the script specific and post
+	// composition version exclusions listed in CompositionExclusions.txt, the
+	// singletons and the non-starter decompositions. It is worked out here and
+	// checked against the property in DerivedNormalizationProps.txt
+	excluded := map[rune]bool{}
+	for _, r := range ucd.Ranges("CompositionExclusions.txt", func(fields []string) string { return "excluded" }) {
+		for c := r.Lo; c <= r.Hi; c++ {
+			excluded[c] = true
+		}
+	}
+	classOf := func(r rune) string {
+		for _, c := range classes {
+			if c.Lo <= r && r <= c.Hi {
+				return c.Value
+			}
+		}
+		return "0"
+	}
+	for r, m := range mappings {
+		if !m.compat && (len(m.runes) == 1 || classOf(r) != "0" || classOf(m.runes[0]) != "0") {
+			excluded[r] = true
+		}
+	}
+	derived := 0
+	for _, r := range ucd.Ranges("DerivedNormalizationProps.txt", func(fields []string) string {
+		if fields[1] == "Full_Composition_Exclusion" {
+			return fields[1]
+		}
+		return ""
+	}) {
+		for c := r.Lo; c <= r.Hi; c++ {
+			if !excluded[c] {
+				log.Fatalf("%04X is Full_Composition_Exclusion but not excluded", c)
+			}
+			derived++
+		}
+	}
+	if derived != len(excluded) {
+		log.Fatalf("%d characters are excluded from composition, Full_Composition_Exclusion has %d", len(excluded), derived)
+	}
+
+	// Applies the mappings over and over until nothing changes any more
+	var decompose func(r rune, compat bool) []rune
+	decompose = func(r rune, compat bool) []rune {
+		m, ok := mappings[r]
+		if !ok || (m.compat && !compat) {
+			return []rune{r}
+		}
+		var out []rune
+		for _, c := range m.runes {
+			out = append(out, decompose(c, compat)...)
+		}
+		return out
+	}
+
+	var code []rune
+	for r := range mappings {
+		code = append(code, r)
+	}
+	sort.Slice(code, func(i, j int) bool { return code[i] < code[j] })
+
+	// Primary composites are the canonical two character mappings that are
+	// not excluded from composition
+	type composition struct{ first, second, composite rune }
+	var compositions []composition
+	seconds := map[rune]bool{}
+	for _, r := range code {
+		m := mappings[r]
+		if m.compat || len(m.runes) != 2 || excluded[r] {
+			continue
+		}
+		compositions = append(compositions, composition{m.runes[0], m.runes[1], r})
+		seconds[m.runes[1]] = true
+	}
+	sort.Slice(compositions, func(i, j int) bool {
+		if compositions[i].first != compositions[j].first {
+			return compositions[i].first < compositions[j].first
+		}
+		return compositions[i].second < compositions[j].second
+	})
+	var secondList []rune
+	for r := range seconds {
+		secondList = append(secondList, r)
+	}
+	sort.Slice(secondList, func(i, j int) bool { return secondList[i] < secondList[j] })
+
+	var buf bytes.Buffer
+	buf.WriteString(ucd.Header("genNormTables.go"))
+	fmt.Fprintf(&buf, "// The version of the Unicode Character Database the tables were generated from\n")
+	fmt.Fprintf(&buf, "const normUnicodeVersion = %q\n\n", *ucd.Version)
+	fmt.Fprintf(&buf, "// Canonical_Combining_Class values other than 0 sorted by code point\n")
+	fmt.Fprintf(&buf, "var normCombiningClassTable = [...]normClassRange{\n")
+	for _, r := range classes {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, %s},\n", r.Lo, r.Hi, r.Value)
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "// Full canonical and compatibility decompositions sorted by code point, the\n")
+	fmt.Fprintf(&buf, "// canonical one is empty when there is none. Hangul syllables are decomposed by code\n")
+	fmt.Fprintf(&buf, "var normDecompositionTable = [...]normDecomposition{\n")
+	for _, r := range code {
+		canonical := ""
+		if !mappings[r].compat {
+			canonical = string(decompose(r, false))
+		}
+		fmt.Fprintf(&buf, "\t{0x%04X, %+q, %+q},\n", r, canonical, string(decompose(r, true)))
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "// Pairs that compose canonically sorted by the first and then the second character\n")
+	fmt.Fprintf(&buf, "var normCompositionTable = [...]normComposition{\n")
+	for _, c := range compositions {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X, 0x%04X},\n", c.first, c.second, c.composite)
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "// Characters that compose with the character before them, the second\n")
+	fmt.Fprintf(&buf, "// characters of the pairs above\n")
+	fmt.Fprintf(&buf, "var normSecondTable = [...]rune{\n")
+	for i, r := range secondList {
+		if i%8 == 0 {
+			buf.WriteString("\t")
+		}
+		fmt.Fprintf(&buf, "0x%04X,", r)
+		if i%8 == 7 || i == len(secondList)-1 {
+			buf.WriteString("\n")
+		} else {
+			buf.WriteString(" ")
+		}
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	ucd.WriteGo("normTables.go", buf.Bytes())
+}
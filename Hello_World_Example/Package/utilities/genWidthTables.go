@@ -0,0 +1,55 @@
+//go:build ignore
+
+// This program generates widthTables.go, the code points that
+// EastAsianWidth.txt gives as Wide or Fullwidth. Package ucd has the flags it
+// takes
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities/internal/ucd"
+)
+
+func main() {
+	flag.Parse()
+	version := ucd.FileVersion("EastAsianWidth.txt")
+
+	// The unlisted code points of the CJK blocks default to wide, so the
+	// defaults are filled in first and the listed values written over them
+	values := make([]string, 0x110000)
+	for _, r := range ucd.Missing("EastAsianWidth.txt") {
+		for c := r.Lo; c <= r.Hi; c++ {
+			values[c] = r.Value
+		}
+	}
+	for _, r := range ucd.Ranges("EastAsianWidth.txt", func(fields []string) string { return fields[1] }) {
+		for c := r.Lo; c <= r.Hi; c++ {
+			values[c] = r.Value
+		}
+	}
+
+	// Only Wide and Fullwidth take two columns, everything else takes one
+	var wide []ucd.Range
+	for c, v := range values {
+		if v == "W" || v == "F" {
+			wide = append(wide, ucd.Range{Lo: rune(c), Hi: rune(c), Value: "wide"})
+		}
+	}
+	wide = ucd.Merge(wide)
+
+	var buf bytes.Buffer
+	buf.WriteString(ucd.Header("genWidthTables.go"))
+	fmt.Fprintf(&buf, "// The version of the Unicode Character Database the tables were generated from\n")
+	fmt.Fprintf(&buf, "const widthUnicodeVersion = %q\n\n", version)
+	fmt.Fprintf(&buf, "// Code points with an East_Asian_Width of Wide or Fullwidth sorted by code point\n")
+	fmt.Fprintf(&buf, "var wideTable = [...]widthRange{\n")
+	for _, r := range wide {
+		fmt.Fprintf(&buf, "\t{0x%04X, 0x%04X},\n", r.Lo, r.Hi)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	ucd.WriteGo("widthTables.go", buf.Bytes())
+}
@@ -0,0 +1,211 @@
+package utilities
+
+import "iter"
+
+// Generic helpers for slices of any type. Every function comes in a slice
+// version and a version ending in Seq that works on iter.Seq values, so that
+// it can be used with range-over-func
+/*
+for chunk := range utilities.ChunkSeq(slices.Values(names), 10) {
+	...
+}
+*/
+
+// Pair holds one element of each input to Zip
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// ReverseSlice reverses s in place using the same two index swap as Reverse
+func ReverseSlice[T any](s []T) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// ReverseSeq yields the values of seq back to front, it has to read all of seq
+// before it can yield the first value
+func ReverseSeq[T any](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var all []T
+		for v := range seq {
+			all = append(all, v)
+		}
+		for i := len(all) - 1; i >= 0; i-- {
+			if !yield(all[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns a new slice holding f applied to every element of s
+func Map[T, U any](s []T, f func(T) U) []U {
+	out := make([]U, len(s))
+	for i, v := range s {
+		out[i] = f(v)
+	}
+	return out
+}
+
+// MapSeq yields f applied to every value of seq
+func MapSeq[T, U any](seq iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a new slice holding the elements of s that keep returns true for
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var out []T
+	for _, v := range s {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// FilterSeq yields the values of seq that keep returns true for
+func FilterSeq[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce folds s into a single value, starting from initial and calling f with
+// the running value and every element from left to right
+func Reduce[T, U any](s []T, initial U, f func(U, T) U) U {
+	acc := initial
+	for _, v := range s {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// ReduceSeq folds seq into a single value like Reduce
+func ReduceSeq[T, U any](seq iter.Seq[T], initial U, f func(U, T) U) U {
+	acc := initial
+	for v := range seq {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Chunk splits s into consecutive pieces of n elements, the last one may be
+// shorter. The pieces share memory with s. Chunk panics if n is less than 1
+func Chunk[T any](s []T, n int) [][]T {
+	if n < 1 {
+		panic("utilities: chunk size must be at least 1")
+	}
+	var out [][]T
+	for i := 0; i < len(s); i += n {
+		out = append(out, s[i:min(i+n, len(s)):min(i+n, len(s))])
+	}
+	return out
+}
+
+// ChunkSeq yields consecutive pieces of n values from seq, every piece is a new slice
+func ChunkSeq[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("utilities: chunk size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		var chunk []T
+		for v := range seq {
+			chunk = append(chunk, v)
+			if len(chunk) == n {
+				if !yield(chunk) {
+					return
+				}
+				chunk = nil
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Window returns every run of n neighbouring elements of s, moving one element
+// at a time. The windows share memory with s. Window panics if n is less than 1
+func Window[T any](s []T, n int) [][]T {
+	if n < 1 {
+		panic("utilities: window size must be at least 1")
+	}
+	var out [][]T
+	for i := 0; i+n <= len(s); i++ {
+		out = append(out, s[i:i+n:i+n])
+	}
+	return out
+}
+
+// WindowSeq yields every run of n neighbouring values of seq, every window is a new slice
+func WindowSeq[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("utilities: window size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		window := make([]T, 0, n)
+		for v := range seq {
+			if len(window) == n {
+				window = append(window[:0:0], window[1:]...)
+			}
+			window = append(window, v)
+			if len(window) == n && !yield(window) {
+				return
+			}
+		}
+	}
+}
+
+// Zip pairs up the elements of a and b by index, it stops at the shorter one
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	out := make([]Pair[A, B], min(len(a), len(b)))
+	for i := range out {
+		out[i] = Pair[A, B]{a[i], b[i]}
+	}
+	return out
+}
+
+// ZipSeq yields the values of a and b side by side, it stops at the shorter one
+func ZipSeq[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		next, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := next()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// Partition splits s into the elements that match returns true for and the rest,
+// both keep the order of s
+func Partition[T any](s []T, match func(T) bool) (matched, rest []T) {
+	for _, v := range s {
+		if match(v) {
+			matched = append(matched, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+	return matched, rest
+}
+
+// PartitionSeq returns the values of seq that match returns true for and the
+// rest, every returned sequence ranges over seq on its own
+func PartitionSeq[T any](seq iter.Seq[T], match func(T) bool) (matched, rest iter.Seq[T]) {
+	return FilterSeq(seq, match), FilterSeq(seq, func(v T) bool { return !match(v) })
+}
@@ -0,0 +1,123 @@
+package utilities
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestReverseSlice(t *testing.T) {
+	cases := []struct {
+		in, want []int
+	}{
+		{[]int{1, 2, 3, 4}, []int{4, 3, 2, 1}},
+		{[]int{1, 2, 3}, []int{3, 2, 1}},
+		{[]int{1}, []int{1}},
+		{nil, nil},
+	}
+
+	for _, c := range cases {
+		got := slices.Clone(c.in)
+		ReverseSlice(got)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("ReverseSlice(%v) == %v, want %v", c.in, got, c.want)
+		}
+		if got := slices.Collect(ReverseSeq(slices.Values(c.in))); !slices.Equal(got, c.want) {
+			t.Errorf("ReverseSeq(%v) == %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestMapFilterReduce(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5}
+	even := func(v int) bool { return v%2 == 0 }
+	sum := func(acc, v int) int { return acc + v }
+
+	if got, want := Map(in, strconv.Itoa), []string{"1", "2", "3", "4", "5"}; !slices.Equal(got, want) {
+		t.Errorf("Map == %q, want %q", got, want)
+	}
+	if got, want := slices.Collect(MapSeq(slices.Values(in), strconv.Itoa)), []string{"1", "2", "3", "4", "5"}; !slices.Equal(got, want) {
+		t.Errorf("MapSeq == %q, want %q", got, want)
+	}
+	if got, want := Filter(in, even), []int{2, 4}; !slices.Equal(got, want) {
+		t.Errorf("Filter == %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(FilterSeq(slices.Values(in), even)), []int{2, 4}; !slices.Equal(got, want) {
+		t.Errorf("FilterSeq == %v, want %v", got, want)
+	}
+	if got := Reduce(in, 10, sum); got != 25 {
+		t.Errorf("Reduce == %d, want 25", got)
+	}
+	if got := ReduceSeq(slices.Values(in), 10, sum); got != 25 {
+		t.Errorf("ReduceSeq == %d, want 25", got)
+	}
+	// Reduce can also change the type, here the ints are joined into a string
+	if got := Reduce(in, "", func(acc string, v int) string { return acc + strconv.Itoa(v) }); got != "12345" {
+		t.Errorf("Reduce == %q, want %q", got, "12345")
+	}
+}
+
+func TestChunkAndWindow(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5}
+	cases := []struct {
+		name string
+		got  [][]int
+		want [][]int
+	}{
+		{"Chunk 2", Chunk(in, 2), [][]int{{1, 2}, {3, 4}, {5}}},
+		{"Chunk 5", Chunk(in, 5), [][]int{{1, 2, 3, 4, 5}}},
+		{"Chunk empty", Chunk([]int{}, 3), nil},
+		{"ChunkSeq 2", slices.Collect(ChunkSeq(slices.Values(in), 2)), [][]int{{1, 2}, {3, 4}, {5}}},
+		{"Window 3", Window(in, 3), [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}},
+		{"Window 6", Window(in, 6), nil},
+		{"WindowSeq 3", slices.Collect(WindowSeq(slices.Values(in), 3)), [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}},
+		{"WindowSeq 6", slices.Collect(WindowSeq(slices.Values(in), 6)), nil},
+	}
+
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s == %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// Appending to a chunk must not overwrite the next one
+	chunks := Chunk(in, 2)
+	_ = append(chunks[0], 99)
+	if in[2] != 3 {
+		t.Errorf("appending to a chunk changed the input to %v", in)
+	}
+}
+
+func TestZip(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	counts := []int{1, 2}
+
+	got := Zip(names, counts)
+	want := []Pair[string, int]{{"a", 1}, {"b", 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Zip == %v, want %v", got, want)
+	}
+
+	seq := maps.Collect(ZipSeq(slices.Values(names), slices.Values(counts)))
+	if fmt.Sprint(seq) != "map[a:1 b:2]" {
+		t.Errorf("ZipSeq == %v, want map[a:1 b:2]", seq)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	in := []string{"Hello", "world", "Go", "gopher"}
+	upper := func(s string) bool { return s[0] >= 'A' && s[0] <= 'Z' }
+
+	matched, rest := Partition(in, upper)
+	if !slices.Equal(matched, []string{"Hello", "Go"}) || !slices.Equal(rest, []string{"world", "gopher"}) {
+		t.Errorf("Partition == %q, %q", matched, rest)
+	}
+
+	matchedSeq, restSeq := PartitionSeq(slices.Values(in), upper)
+	if !slices.Equal(slices.Collect(matchedSeq), matched) || !slices.Equal(slices.Collect(restSeq), rest) {
+		t.Errorf("PartitionSeq == %q, %q", slices.Collect(matchedSeq), slices.Collect(restSeq))
+	}
+}
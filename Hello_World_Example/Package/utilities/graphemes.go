@@ -0,0 +1,213 @@
+package utilities
+
+import (
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// The tables in graphemeTables.go are generated from the Unicode Character Database
+//go:generate go run genGraphemeTables.go
+
+// Grapheme_Cluster_Break property values from UAX #29, Extended_Pictographic
+// is stored as one more value since the two never overlap
+type graphemeProperty uint8
+
+const (
+	gbOther graphemeProperty = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbPrepend
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+	gbExtendedPictographic
+)
+
+// Indic_Conjunct_Break property values used by rule GB9c
+type conjunctProperty uint8
+
+const (
+	incbNone conjunctProperty = iota
+	incbConsonant
+	incbExtend
+	incbLinker
+)
+
+type graphemeRange struct {
+	lo, hi rune
+	prop   graphemeProperty
+}
+
+type conjunctRange struct {
+	lo, hi rune
+	prop   conjunctProperty
+}
+
+// Binary searches the generated table for the break property of r
+func breakProperty(r rune) graphemeProperty {
+	lo, hi := 0, len(graphemeBreakTable)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch e := &graphemeBreakTable[m]; {
+		case r < e.lo:
+			hi = m
+		case r > e.hi:
+			lo = m + 1
+		default:
+			return e.prop
+		}
+	}
+	return gbOther
+}
+
+// Binary searches the generated table for the conjunct property of r
+func conjunctBreakProperty(r rune) conjunctProperty {
+	lo, hi := 0, len(conjunctBreakTable)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch e := &conjunctBreakTable[m]; {
+		case r < e.lo:
+			hi = m
+		case r > e.hi:
+			lo = m + 1
+		default:
+			return e.prop
+		}
+	}
+	return incbNone
+}
+
+// Returns the length in bytes of the first extended grapheme cluster in s.
+// The rules are checked in the order UAX #29 lists them, GB1 and GB2 are
+// covered by the loop bounds. Invalid UTF-8 bytes are treated as "Other"
+func firstGraphemeLen(s string) int {
+	if s == "" {
+		return 0
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	prev := breakProperty(r)
+
+	// Number of regional indicators directly before the current position
+	regional := 0
+	if prev == gbRegionalIndicator {
+		regional = 1
+	}
+	// 1 after Extended_Pictographic Extend*, 2 once a ZWJ follows that
+	emoji := 0
+	if prev == gbExtendedPictographic {
+		emoji = 1
+	}
+	// 1 after a conjunct consonant followed by extenders, 2 once a linker was seen
+	conjunct := 0
+	if conjunctBreakProperty(r) == incbConsonant {
+		conjunct = 1
+	}
+
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		next := breakProperty(r)
+		nextConjunct := conjunctBreakProperty(r)
+
+		joined := false
+		switch {
+		case prev == gbCR && next == gbLF: // GB3
+			joined = true
+		case prev == gbCR || prev == gbLF || prev == gbControl: // GB4
+		case next == gbCR || next == gbLF || next == gbControl: // GB5
+		case prev == gbL && (next == gbL || next == gbV || next == gbLV || next == gbLVT): // GB6
+			joined = true
+		case (prev == gbLV || prev == gbV) && (next == gbV || next == gbT): // GB7
+			joined = true
+		case (prev == gbLVT || prev == gbT) && next == gbT: // GB8
+			joined = true
+		case next == gbExtend || next == gbZWJ || next == gbSpacingMark || prev == gbPrepend: // GB9, GB9a, GB9b
+			joined = true
+		case conjunct == 2 && nextConjunct == incbConsonant: // GB9c
+			joined = true
+		case emoji == 2 && next == gbExtendedPictographic: // GB11
+			joined = true
+		case prev == gbRegionalIndicator && next == gbRegionalIndicator && regional%2 == 1: // GB12, GB13
+			joined = true
+		}
+		if !joined {
+			break
+		}
+
+		// Update the state for the sequences that span more than two runes
+		switch {
+		case next == gbExtendedPictographic:
+			emoji = 1
+		case emoji == 1 && next == gbExtend:
+		case emoji == 1 && next == gbZWJ:
+			emoji = 2
+		default:
+			emoji = 0
+		}
+		switch {
+		case nextConjunct == incbConsonant:
+			conjunct = 1
+		case conjunct > 0 && nextConjunct == incbLinker:
+			conjunct = 2
+		case conjunct > 0 && nextConjunct == incbExtend:
+		default:
+			conjunct = 0
+		}
+		if next == gbRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = next
+		n += size
+	}
+	return n
+}
+
+// Graphemes returns an iterator over the extended grapheme clusters of s as
+// defined by UAX #29, the clusters are what a reader sees as single characters
+/*
+for g := range utilities.Graphemes("é!") {
+	fmt.Println(g) // prints "é" and then "!"
+}
+*/
+func Graphemes(s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for s != "" {
+			n := firstGraphemeLen(s)
+			if !yield(s[:n]) {
+				return
+			}
+			s = s[n:]
+		}
+	}
+}
+
+// ReverseGraphemes returns its argument string reversed grapheme cluster-wise,
+// unlike Reverse it keeps combining marks, flags and emoji sequences intact
+func ReverseGraphemes(inputString string) string {
+	// Remember where every cluster ends so they can be copied back to front
+	var ends []int
+	for n := 0; n < len(inputString); {
+		n += firstGraphemeLen(inputString[n:])
+		ends = append(ends, n)
+	}
+
+	var b strings.Builder
+	b.Grow(len(inputString))
+	for i := len(ends) - 1; i >= 0; i-- {
+		start := 0
+		if i > 0 {
+			start = ends[i-1]
+		}
+		b.WriteString(inputString[start:ends[i]])
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package utilities
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReverseGraphemes(t *testing.T) {
+	// Specify the test cases, the input is in and want is the second value in each set
+	cases := []struct {
+		in, want string
+	}{
+		{"Hello, world", "dlrow ,olleH"},
+		{"Hello, 世界", "界世 ,olleH"},
+		{"", ""},
+		// e followed by U+0301 COMBINING ACUTE ACCENT
+		{"cafe\u0301!", "!e\u0301fac"},
+		// Flags are pairs of regional indicators, JP then US
+		{"\U0001F1EF\U0001F1F5\U0001F1FA\U0001F1F8", "\U0001F1FA\U0001F1F8\U0001F1EF\U0001F1F5"},
+		// Family emoji joined with U+200D ZERO WIDTH JOINER
+		{"a\U0001F468\u200d\U0001F469\u200d\U0001F467b", "b\U0001F468\u200d\U0001F469\u200d\U0001F467a"},
+		// Hangul syllables written as conjoining jamo, 한 then 글
+		{"\u1112\u1161\u11ab\u1100\u1173\u11af", "\u1100\u1173\u11af\u1112\u1161\u11ab"},
+		{"a\r\nb", "b\r\na"},
+	}
+
+	for _, c := range cases {
+		got := ReverseGraphemes(c.in)
+		if got != c.want {
+			t.Errorf("ReverseGraphemes(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"abc", []string{"a", "b", "c"}},
+		{"e\u0301\u0302x", []string{"e\u0301\u0302", "x"}},
+		{"\U0001F44D\U0001F3FD!", []string{"\U0001F44D\U0001F3FD", "!"}},
+		// Devanagari क्षि is a single conjunct (rule GB9c)
+		{"\u0915\u094d\u0937\u093f", []string{"\u0915\u094d\u0937\u093f"}},
+		// Invalid bytes come out one at a time
+		{"a\xff\xfeb", []string{"a", "\xff", "\xfe", "b"}},
+	}
+
+	for _, c := range cases {
+		got := slices.Collect(Graphemes(c.in))
+		if !slices.Equal(got, c.want) {
+			t.Errorf("Graphemes(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+// Runs every case of the Unicode conformance file in testdata, each line lists
+// code points separated by ÷ where a break is expected and × where it is not
+func TestGraphemesConformance(t *testing.T) {
+	f, err := os.Open("testdata/GraphemeBreakTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		var want []string
+		var cluster, input strings.Builder
+		for _, field := range strings.Fields(text) {
+			switch field {
+			case "÷":
+				if cluster.Len() > 0 {
+					want = append(want, cluster.String())
+					cluster.Reset()
+				}
+			case "×":
+			default:
+				cp, err := strconv.ParseUint(field, 16, 32)
+				if err != nil {
+					t.Fatalf("line %d: %v", line, err)
+				}
+				cluster.WriteRune(rune(cp))
+				input.WriteRune(rune(cp))
+			}
+		}
+
+		got := slices.Collect(Graphemes(input.String()))
+		if !slices.Equal(got, want) {
+			t.Errorf("line %d: Graphemes(%+q) == %+q, want %+q", line, input.String(), got, want)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
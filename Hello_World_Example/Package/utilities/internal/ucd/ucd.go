@@ -0,0 +1,239 @@
+// Package ucd reads files of the Unicode Character Database, it is shared by the
+// programs that generate the tables of the utilities package. They are run by
+// "go generate" inside of the utilities package, or by hand with the files in
+// a local directory:
+/*
+go run genGraphemeTables.go -ucd /path/to/ucd
+*/
+// When -ucd is not given the files are downloaded from unicode.org, for the
+// version given with -version
+package ucd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The flags every generator understands
+var (
+	Dir     = flag.String("ucd", "", "local directory holding the Unicode Character Database")
+	Version = flag.String("version", "17.0.0", "version of the Unicode Character Database to download when -ucd is empty")
+)
+
+// Range says that the code points Lo through Hi have the property value Value
+type Range struct {
+	Lo, Hi rune
+	Value  string
+}
+
+// Open returns the file called name, for example "auxiliary/GraphemeBreakProperty.txt",
+// from the -ucd directory or from unicode.org. Generators stop on any error
+func Open(name string) io.ReadCloser {
+	if *Dir != "" {
+		f, err := os.Open(filepath.Join(*Dir, filepath.FromSlash(name)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return f
+	}
+	url := "https://www.unicode.org/Public/" + *Version + "/ucd/" + name
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", url, resp.Status)
+	}
+	return resp.Body
+}
+
+// Fields calls fn with the semicolon separated and trimmed fields of every line of
+// the file called name, comments and empty lines are skipped
+func Fields(name string, fn func(fields []string)) {
+	r := Open(name)
+	defer r.Close()
+	if err := ParseFields(r, fn); err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+}
+
+// ParseFields is Fields for any reader
+func ParseFields(r io.Reader, fn func(fields []string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		fn(fields)
+	}
+	return scanner.Err()
+}
+
+// UnicodeData calls fn with the fields of every line of UnicodeData.txt and the
+// code points the line stands for. The "<CJK Ideograph, First>" and "<CJK Ideograph,
+// Last>" lines of the large blocks are passed on as a single call
+func UnicodeData(fn func(lo, hi rune, fields []string)) {
+	var first rune
+	Fields("UnicodeData.txt", func(fields []string) {
+		r, _, err := CodePoints(fields[0])
+		if err != nil {
+			log.Fatalf("UnicodeData.txt: %v", err)
+		}
+		switch {
+		case strings.HasSuffix(fields[1], ", First>"):
+			first = r
+		case strings.HasSuffix(fields[1], ", Last>"):
+			fn(first, r, fields)
+		default:
+			fn(r, r, fields)
+		}
+	})
+}
+
+// Ranges reads a property file of the form "0041..005A ; Value". pick returns the
+// value to keep for the fields of a line or "" to skip the line
+func Ranges(name string, pick func(fields []string) string) []Range {
+	var ranges []Range
+	Fields(name, func(fields []string) {
+		value := pick(fields)
+		if value == "" {
+			return
+		}
+		lo, hi, err := CodePoints(fields[0])
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		ranges = append(ranges, Range{lo, hi, value})
+	})
+	return ranges
+}
+
+// Missing returns the defaults a file gives in "# @missing: 3400..4DBF; W" lines for
+// the code points it does not list, later lines take precedence over earlier ones
+func Missing(name string) []Range {
+	r := Open(name)
+	defer r.Close()
+	var ranges []Range
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, found := strings.CutPrefix(scanner.Text(), "# @missing:")
+		if !found {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		lo, hi, err := CodePoints(strings.TrimSpace(fields[0]))
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		ranges = append(ranges, Range{lo, hi, strings.TrimSpace(fields[1])})
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	return ranges
+}
+
+// FileVersion returns the version a file gives in its first line, "17.0.0" for
+// a file starting with "# DerivedCoreProperties-17.0.0.txt"
+func FileVersion(name string) string {
+	r := Open(name)
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		log.Fatalf("%s: the file is empty: %v", name, scanner.Err())
+	}
+	prefix := "# " + strings.TrimSuffix(path.Base(name), ".txt") + "-"
+	version, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), prefix)
+	version, isText := strings.CutSuffix(version, ".txt")
+	if !found || !isText {
+		log.Fatalf("%s: the first line %q does not give a version", name, scanner.Text())
+	}
+	return version
+}
+
+// SameVersion returns the version of the files called names and stops when two
+// of them differ, so that a table is never made from files of different versions
+func SameVersion(names ...string) string {
+	version := FileVersion(names[0])
+	for _, name := range names[1:] {
+		if v := FileVersion(name); v != version {
+			log.Fatalf("%s is version %s but %s is version %s", names[0], version, name, v)
+		}
+	}
+	return version
+}
+
+// CodePoints parses "0041" or "0041..005A"
+func CodePoints(s string) (lo, hi rune, err error) {
+	first, last, found := strings.Cut(s, "..")
+	l, err := strconv.ParseUint(first, 16, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	h := l
+	if found {
+		if h, err = strconv.ParseUint(last, 16, 32); err != nil {
+			return 0, 0, err
+		}
+	}
+	return rune(l), rune(h), nil
+}
+
+// Runes parses a list of code points such as "0044 0307"
+func Runes(s string) ([]rune, error) {
+	var runes []rune
+	for _, field := range strings.Fields(s) {
+		r, err := strconv.ParseUint(field, 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, rune(r))
+	}
+	return runes, nil
+}
+
+// Merge sorts the ranges and joins neighbours that carry the same value
+func Merge(ranges []Range) []Range {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Lo < ranges[j].Lo })
+	var merged []Range
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && merged[n-1].Hi+1 == r.Lo && merged[n-1].Value == r.Value {
+			merged[n-1].Hi = r.Hi
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// WriteGo formats the Go source in src and writes it to the file called name
+func WriteGo(name string, src []byte) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	if err := os.WriteFile(name, formatted, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Header returns the first lines every generated file starts with
+func Header(generator string) string {
+	return fmt.Sprintf("// Code generated by %s; DO NOT EDIT.\n\npackage utilities\n\n", generator)
+}
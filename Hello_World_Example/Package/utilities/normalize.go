@@ -0,0 +1,365 @@
+package utilities
+
+import (
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// The tables in normTables.go are generated from the Unicode Character Database
+//go:generate go run genNormTables.go
+
+// Form is one of the Unicode normalization forms from UAX #15. The zero value
+// is no form at all and leaves text as it is
+type Form int
+
+const (
+	// NFC decomposes canonically and composes again, e and U+0301 become U+00E9
+	NFC Form = iota + 1
+	// NFD decomposes canonically, U+00E9 becomes e and U+0301
+	NFD
+	// NFKC also replaces compatibility characters, the ligature U+FB01 becomes "fi"
+	NFKC
+	// NFKD is NFD that also replaces compatibility characters
+	NFKD
+)
+
+func (f Form) String() string {
+	switch f {
+	case NFC:
+		return "NFC"
+	case NFD:
+		return "NFD"
+	case NFKC:
+		return "NFKC"
+	case NFKD:
+		return "NFKD"
+	}
+	return "none"
+}
+
+func (f Form) compat() bool  { return f == NFKC || f == NFKD }
+func (f Form) compose() bool { return f == NFC || f == NFKC }
+
+type normClassRange struct {
+	lo, hi rune
+	class  uint8
+}
+
+type normDecomposition struct {
+	r                 rune
+	canonical, compat string
+}
+
+type normComposition struct {
+	first, second, composite rune
+}
+
+// Hangul syllables are built from leading consonants, vowels and trailing
+// consonants by arithmetic instead of tables
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// Returns the Canonical_Combining_Class of r, 0 for starters
+func combiningClass(r rune) uint8 {
+	lo, hi := 0, len(normCombiningClassTable)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch e := &normCombiningClassTable[m]; {
+		case r < e.lo:
+			hi = m
+		case r > e.hi:
+			lo = m + 1
+		default:
+			return e.class
+		}
+	}
+	return 0
+}
+
+// Appends the full decomposition of r to dst
+func appendDecomposition(dst []rune, r rune, compat bool) []rune {
+	if s := r - hangulSBase; s >= 0 && s < hangulSCount {
+		dst = append(dst, hangulLBase+s/hangulNCount, hangulVBase+(s%hangulNCount)/hangulTCount)
+		if t := s % hangulTCount; t != 0 {
+			dst = append(dst, hangulTBase+t)
+		}
+		return dst
+	}
+	i, found := slices.BinarySearchFunc(normDecompositionTable[:], r, func(d normDecomposition, r rune) int {
+		return int(d.r - r)
+	})
+	if found {
+		d := normDecompositionTable[i]
+		if compat {
+			return append(dst, []rune(d.compat)...)
+		}
+		if d.canonical != "" {
+			return append(dst, []rune(d.canonical)...)
+		}
+	}
+	return append(dst, r)
+}
+
+// Returns the primary composite of a and b if there is one
+func composePair(a, b rune) (rune, bool) {
+	if l, v := a-hangulLBase, b-hangulVBase; l >= 0 && l < hangulLCount && v >= 0 && v < hangulVCount {
+		return hangulSBase + (l*hangulVCount+v)*hangulTCount, true
+	}
+	if s, t := a-hangulSBase, b-hangulTBase; s >= 0 && s < hangulSCount && s%hangulTCount == 0 && t > 0 && t < hangulTCount {
+		return a + t, true
+	}
+	i, found := slices.BinarySearchFunc(normCompositionTable[:], [2]rune{a, b}, func(c normComposition, key [2]rune) int {
+		if c.first != key[0] {
+			return int(c.first - key[0])
+		}
+		return int(c.second - key[1])
+	})
+	if !found {
+		return 0, false
+	}
+	return normCompositionTable[i].composite, true
+}
+
+// Reports whether r can compose with the character before it
+func composesBackward(r rune) bool {
+	if r >= hangulVBase && r < hangulVBase+hangulVCount || r > hangulTBase && r < hangulTBase+hangulTCount {
+		return true
+	}
+	_, found := slices.BinarySearch(normSecondTable[:], r)
+	return found
+}
+
+// Normalize returns s in the normalization form. Bytes that are not valid
+// UTF-8 are kept as they are and nothing composes across them
+/*
+utilities.Normalize(utilities.NFC, "e\u0301") == "\u00e9"
+*/
+func Normalize(form Form, s string) string {
+	if form < NFC || form > NFKD {
+		return s
+	}
+	return string(appendNormalized(make([]byte, 0, len(s)), form, s))
+}
+
+// Appends s in the normalization form to dst, invalid bytes split the text into
+// pieces that are normalized one at a time
+func appendNormalized(dst []byte, form Form, s string) []byte {
+	if form < NFC || form > NFKD {
+		return append(dst, s...)
+	}
+	start := 0
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && n == 1 {
+			dst = appendNormalizedValid(dst, form, s[start:i])
+			dst = append(dst, s[i])
+			start = i + 1
+		}
+		i += n
+	}
+	return appendNormalizedValid(dst, form, s[start:])
+}
+
+// Normalizes valid UTF-8, rules D117 and D118 of chapter 3 of the Unicode Standard
+func appendNormalizedValid(dst []byte, form Form, s string) []byte {
+	// ASCII is the same in every form
+	ascii := true
+	for i := 0; i < len(s) && ascii; i++ {
+		ascii = s[i] < utf8.RuneSelf
+	}
+	if ascii {
+		return append(dst, s...)
+	}
+
+	var runes []rune
+	for _, r := range s {
+		runes = appendDecomposition(runes, r, form.compat())
+	}
+
+	// Canonical ordering, every run of nonspacing marks is sorted by class
+	// without changing the order of marks of the same class
+	classes := make([]uint8, len(runes))
+	for i, r := range runes {
+		classes[i] = combiningClass(r)
+	}
+	for i := 1; i < len(runes); i++ {
+		for j := i; j > 0 && classes[j] != 0 && classes[j-1] > classes[j]; j-- {
+			runes[j], runes[j-1] = runes[j-1], runes[j]
+			classes[j], classes[j-1] = classes[j-1], classes[j]
+		}
+	}
+
+	if form.compose() {
+		runes = composeRunes(runes, classes)
+	}
+	for _, r := range runes {
+		dst = utf8.AppendRune(dst, r)
+	}
+	return dst
+}
+
+// Canonical composition, every character that is not blocked from the last
+// starter is combined with it when the pair has a primary composite
+func composeRunes(runes []rune, classes []uint8) []rune {
+	out := runes[:0]
+	starter := -1
+	var last uint8 // Class of the last character kept after the starter
+	for i, r := range runes {
+		class := classes[i]
+		if starter >= 0 && (len(out)-1 == starter || (last != 0 && last < class)) {
+			if composite, ok := composePair(out[starter], r); ok {
+				out[starter] = composite
+				continue
+			}
+		}
+		out = append(out, r)
+		if class == 0 {
+			starter = len(out) - 1
+		}
+		last = class
+	}
+	return out
+}
+
+// Reports whether the text before r normalizes the same no matter what follows,
+// which is the case when r starts with a starter that nothing composes into
+func normBoundaryBefore(form Form, r rune) bool {
+	var buf [4]rune
+	first := appendDecomposition(buf[:0], r, form.compat())[0]
+	return combiningClass(first) == 0 && !(form.compose() && composesBackward(first))
+}
+
+// Finds where the text held back by NormalizeReader and NormalizeWriter can be
+// split. It remembers how far it has looked, so every byte is decoded once no
+// matter how long a run of combining marks gets
+type normSplitter struct {
+	form    Form
+	scanned int // Leading bytes of the held back text looked at so far
+	safe    int // The last boundary found in them
+}
+
+// Returns how many leading bytes of p can be normalized without seeing the
+// bytes that follow. Everything can be normalized once the input has ended
+func (s *normSplitter) safeLen(p []byte, atEOF bool) int {
+	if atEOF {
+		return len(p)
+	}
+	for s.scanned < len(p) && utf8.FullRune(p[s.scanned:]) {
+		r, n := utf8.DecodeRune(p[s.scanned:])
+		if (r == utf8.RuneError && n == 1) || normBoundaryBefore(s.form, r) {
+			s.safe = s.scanned
+		}
+		s.scanned += n
+	}
+	return s.safe
+}
+
+// Returns normSplitter.safeLen for text that is looked at only once
+func normSafeLen(form Form, p []byte, atEOF bool) int {
+	s := normSplitter{form: form}
+	return s.safeLen(p, atEOF)
+}
+
+// Forgets the first n bytes of the held back text once they were normalized
+func (s *normSplitter) drop(n int) {
+	s.scanned = max(s.scanned-n, 0)
+	s.safe = max(s.safe-n, 0)
+}
+
+// NormalizeReader normalizes the text it reads from another reader. Text is
+// held back until a character that starts a new piece arrives, so a long run of
+// combining marks stays in memory until it ends
+type NormalizeReader struct {
+	r     io.Reader
+	form  Form
+	split normSplitter
+	in    []byte // Read but not normalized yet
+	out   []byte // Normalized but not handed out yet
+	err   error  // From r, returned once everything before it is handed out
+}
+
+// NewNormalizeReader returns a reader that reads r in the normalization form
+/*
+normalized, _ := io.ReadAll(utilities.NewNormalizeReader(utilities.NFC, file))
+*/
+func NewNormalizeReader(form Form, r io.Reader) *NormalizeReader {
+	return &NormalizeReader{r: r, form: form, split: normSplitter{form: form}}
+}
+
+// Read reads normalized text into p
+func (nr *NormalizeReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(nr.out) == 0 {
+		if nr.err != nil {
+			return 0, nr.err
+		}
+		var buf [4096]byte
+		n, err := nr.r.Read(buf[:])
+		nr.in = append(nr.in, buf[:n]...)
+		nr.err = err
+		safe := nr.split.safeLen(nr.in, err != nil)
+		nr.out = appendNormalized(nr.out[:0], nr.form, string(nr.in[:safe]))
+		nr.in = nr.in[:copy(nr.in, nr.in[safe:])]
+		nr.split.drop(safe)
+	}
+	n := copy(p, nr.out)
+	nr.out = nr.out[n:]
+	return n, nil
+}
+
+// NormalizeWriter normalizes the text written to it before passing it on to
+// another writer. Close must be called to write the text that is held back
+type NormalizeWriter struct {
+	w     io.Writer
+	form  Form
+	split normSplitter
+	in    []byte // Written but not normalized yet
+	out   []byte
+}
+
+// NewNormalizeWriter returns a writer that writes to w in the normalization form
+func NewNormalizeWriter(form Form, w io.Writer) *NormalizeWriter {
+	return &NormalizeWriter{w: w, form: form, split: normSplitter{form: form}}
+}
+
+// Write normalizes p and writes all of it that can be normalized already
+func (nw *NormalizeWriter) Write(p []byte) (int, error) {
+	nw.in = append(nw.in, p...)
+	if err := nw.flush(nw.split.safeLen(nw.in, false)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the text that was held back, it does not close the underlying writer
+func (nw *NormalizeWriter) Close() error {
+	return nw.flush(len(nw.in))
+}
+
+// Normalizes and writes the first n bytes held back
+func (nw *NormalizeWriter) flush(n int) error {
+	if n == 0 {
+		return nil
+	}
+	nw.out = appendNormalized(nw.out[:0], nw.form, string(nw.in[:n]))
+	nw.in = nw.in[:copy(nw.in, nw.in[n:])]
+	nw.split.drop(n)
+	_, err := nw.w.Write(nw.out)
+	return err
+}
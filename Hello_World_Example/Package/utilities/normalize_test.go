@@ -0,0 +1,155 @@
+package utilities
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		form     Form
+		in, want string
+	}{
+		{NFC, "", ""},
+		{NFC, "Hello, world", "Hello, world"},
+		// e followed by U+0301 COMBINING ACUTE ACCENT composes to U+00E9
+		{NFC, "cafe\u0301", "caf\u00e9"},
+		{NFD, "caf\u00e9", "cafe\u0301"},
+		// Marks are put in canonical order, U+0323 DOT BELOW before U+0307 DOT ABOVE
+		{NFD, "q\u0307\u0323", "q\u0323\u0307"},
+		{NFC, "s\u0307\u0323", "\u1e69"},
+		// The ligature U+FB01 only changes under the compatibility forms
+		{NFC, "\ufb01", "\ufb01"},
+		{NFKC, "\ufb01", "fi"},
+		{NFKD, "\u2460", "1"},
+		// Hangul syllables are taken apart and put back together by code
+		{NFD, "\ud55c", "\u1112\u1161\u11ab"},
+		{NFC, "\u1112\u1161\u11ab", "\ud55c"},
+		// U+0344 decomposes into two marks and never comes back
+		{NFC, "\u0344", "\u0308\u0301"},
+		// Invalid bytes are kept and nothing composes across them
+		{NFC, "e\xff\u0301", "e\xff\u0301"},
+		{NFD, "\u00e9\xff", "e\u0301\xff"},
+		// The zero Form leaves the text alone
+		{0, "cafe\u0301", "cafe\u0301"},
+	}
+
+	for _, c := range cases {
+		got := Normalize(c.form, c.in)
+		if got != c.want {
+			t.Errorf("Normalize(%v, %+q) == %+q, want %+q", c.form, c.in, got, c.want)
+		}
+	}
+}
+
+// Reading and writing a byte at a time must give what Normalize gives
+func TestNormalizeReaderWriter(t *testing.T) {
+	inputs := []string{
+		"",
+		"cafe\u0301 na\u00efve \ufb01le",
+		"\u1100\u1161\u11a8\uac00",
+		"a\u0301\u0302\u0303\u0304\u0305\u0306\u0307b\xffc\u0327",
+		strings.Repeat("o\u0308", 3000),
+		// Held back as a whole, every byte must only be looked at once
+		"a" + strings.Repeat("\u0301", 50000) + "b",
+	}
+
+	for _, form := range []Form{NFC, NFD, NFKC, NFKD} {
+		for _, in := range inputs {
+			want := Normalize(form, in)
+
+			got, err := io.ReadAll(NewNormalizeReader(form, iotest.OneByteReader(strings.NewReader(in))))
+			if err != nil || string(got) != want {
+				t.Errorf("NormalizeReader(%v, %+.40q) == %+.40q, %v, want %+.40q", form, in, got, err, want)
+			}
+
+			var b bytes.Buffer
+			w := NewNormalizeWriter(form, &b)
+			for i := 0; i < len(in); i++ {
+				w.Write([]byte{in[i]})
+			}
+			if err := w.Close(); err != nil || b.String() != want {
+				t.Errorf("NormalizeWriter(%v, %+.40q) == %+.40q, %v, want %+.40q", form, in, b.String(), err, want)
+			}
+		}
+	}
+}
+
+func TestReverseWithOptionsForm(t *testing.T) {
+	// Composed and decomposed input reverse to the same text
+	for _, in := range []string{"caf\u00e9", "cafe\u0301"} {
+		got, err := ReverseWithOptions(in, ReverseOptions{Form: NFC})
+		if err != nil || got != "\u00e9fac" {
+			t.Errorf("ReverseWithOptions(%+q, NFC) == %+q, %v, want %+q", in, got, err, "\u00e9fac")
+		}
+	}
+	// The offset is that of the text as it was given
+	_, err := ReverseWithOptions("e\u0301\xff", ReverseOptions{Invalid: FailOnInvalid, Form: NFC})
+	if invalid, ok := err.(*InvalidUTF8Error); !ok || invalid.Offset != 3 {
+		t.Errorf("ReverseWithOptions error == %v, want offset 3", err)
+	}
+}
+
+// Runs every case of the conformance file in testdata. Every line holds a source
+// and its NFC, NFD, NFKC and NFKD forms, c1 through c5, and the invariants in
+// the header of the file are checked for all of them
+func TestNormalizeConformance(t *testing.T) {
+	f, err := os.Open("testdata/NormalizationTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// For every form the column it must give and the columns it is applied to
+	invariants := []struct {
+		form Form
+		want int
+		from []int
+	}{
+		{NFC, 2, []int{1, 2, 3}},
+		{NFC, 4, []int{4, 5}},
+		{NFD, 3, []int{1, 2, 3}},
+		{NFD, 5, []int{4, 5}},
+		{NFKC, 4, []int{1, 2, 3, 4, 5}},
+		{NFKD, 5, []int{1, 2, 3, 4, 5}},
+	}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		// @Part lines start the parts of the file
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "@") {
+			continue
+		}
+		// Column 0 is left empty so that c1 is columns[1]
+		columns := []string{""}
+		for _, field := range strings.Split(text, ";")[:5] {
+			var b strings.Builder
+			for _, cp := range strings.Fields(field) {
+				r, err := strconv.ParseUint(cp, 16, 32)
+				if err != nil {
+					t.Fatalf("line %d: %v", line, err)
+				}
+				b.WriteRune(rune(r))
+			}
+			columns = append(columns, b.String())
+		}
+
+		for _, inv := range invariants {
+			for _, from := range inv.from {
+				if got := Normalize(inv.form, columns[from]); got != columns[inv.want] {
+					t.Errorf("line %d: Normalize(%v, c%d %+q) == %+q, want c%d %+q",
+						line, inv.form, from, columns[from], got, inv.want, columns[inv.want])
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package utilities
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// CompareOptions says which differences IsPalindrome and GroupAnagrams should
+// ignore, the text is always compared grapheme cluster by grapheme cluster so
+// that combining marks stay with their letter
+type CompareOptions struct {
+	// Normalize is applied to the text before anything else, use it to make
+	// composed and decomposed text compare equal. Leaving it nil keeps the text as is
+	Normalize func(string) string
+	// FoldCase compares letters without regard to their case
+	FoldCase bool
+	// IgnoreMarks drops nonspacing marks such as accents, together with a
+	// decomposing Normalize this makes "é" and "e" compare equal
+	IgnoreMarks bool
+	// SkipPunctuation leaves out punctuation characters
+	SkipPunctuation bool
+	// SkipSpaces leaves out white space
+	SkipSpaces bool
+}
+
+// DefaultCompareOptions are used by GroupAnagrams, they fold the case and
+// skip punctuation and white space
+var DefaultCompareOptions = CompareOptions{FoldCase: true, SkipPunctuation: true, SkipSpaces: true}
+
+// IsPalindrome reports whether s reads the same backwards as forwards once the
+// differences opts asks for are ignored
+/*
+utilities.IsPalindrome("A man, a plan, a canal: Panama", utilities.DefaultCompareOptions) == true
+*/
+func IsPalindrome(s string, opts CompareOptions) bool {
+	units := canonicalUnits(s, opts)
+	for i, j := 0, len(units)-1; i < j; i, j = i+1, j-1 {
+		if units[i] != units[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupAnagrams puts the words that are anagrams of each other into the same
+// group using DefaultCompareOptions. The groups come in the order their first
+// word appears in and keep the order of the words inside of them
+func GroupAnagrams(words []string) [][]string {
+	return GroupAnagramsWithOptions(words, DefaultCompareOptions)
+}
+
+// GroupAnagramsWithOptions is GroupAnagrams with the given options
+func GroupAnagramsWithOptions(words []string, opts CompareOptions) [][]string {
+	var groups [][]string
+	index := make(map[string]int)
+	for _, word := range words {
+		// Anagrams share the same sorted list of units
+		units := canonicalUnits(word, opts)
+		slices.Sort(units)
+		key := strings.Join(units, "\x00")
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], word)
+	}
+	return groups
+}
+
+// Splits s into grapheme clusters with the changes asked for by opts applied
+func canonicalUnits(s string, opts CompareOptions) []string {
+	if opts.Normalize != nil {
+		s = opts.Normalize(s)
+	}
+
+	var units []string
+	for g := range Graphemes(s) {
+		first := []rune(g)[0]
+		if opts.SkipSpaces && unicode.IsSpace(first) {
+			continue
+		}
+		if opts.SkipPunctuation && unicode.IsPunct(first) {
+			continue
+		}
+		if opts.IgnoreMarks || opts.FoldCase {
+			g = strings.Map(func(r rune) rune {
+				if opts.IgnoreMarks && unicode.Is(unicode.Mn, r) {
+					return -1
+				}
+				if opts.FoldCase {
+					return foldRune(r)
+				}
+				return r
+			}, g)
+			if g == "" {
+				continue
+			}
+		}
+		units = append(units, g)
+	}
+	return units
+}
+
+// Returns the smallest rune that r is equal to under Unicode simple case
+// folding, so "K", "k" and the Kelvin sign all become "K"
+func foldRune(r rune) rune {
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		smallest = min(smallest, f)
+	}
+	return smallest
+}
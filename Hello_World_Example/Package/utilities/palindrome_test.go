@@ -0,0 +1,82 @@
+package utilities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsPalindrome(t *testing.T) {
+	exact := CompareOptions{}
+	loose := DefaultCompareOptions
+	accents := CompareOptions{FoldCase: true, IgnoreMarks: true, SkipPunctuation: true, SkipSpaces: true}
+
+	cases := []struct {
+		in   string
+		opts CompareOptions
+		want bool
+	}{
+		{"", exact, true},
+		{"racecar", exact, true},
+		{"Racecar", exact, false},
+		{"Racecar", loose, true},
+		{"A man, a plan, a canal: Panama", exact, false},
+		{"A man, a plan, a canal: Panama", loose, true},
+		{"Hello, 世界", loose, false},
+		{"上海自来水来自海上", exact, true},
+		// The accent stays on its letter instead of moving to the other side
+		{"e\u0301te\u0301", exact, true},
+		{"e\u0301te", exact, false},
+		{"e\u0301te", accents, true},
+		// Greek final sigma folds to the same letter as the capital
+		{"Σας", loose, true},
+		{"\U0001F600a\U0001F600", exact, true},
+	}
+
+	for _, c := range cases {
+		got := IsPalindrome(c.in, c.opts)
+		if got != c.want {
+			t.Errorf("IsPalindrome(%q, %+v) == %v, want %v", c.in, c.opts, got, c.want)
+		}
+	}
+}
+
+// A normalizer plugged into the options is applied before comparing
+func TestIsPalindromeNormalize(t *testing.T) {
+	compose := func(s string) string { return Normalize(NFC, s) }
+	in := "\u00e9e\u0301"
+
+	if IsPalindrome(in, CompareOptions{}) {
+		t.Errorf("IsPalindrome(%q) == true without normalizing", in)
+	}
+	if !IsPalindrome(in, CompareOptions{Normalize: compose}) {
+		t.Errorf("IsPalindrome(%q) == false after normalizing", in)
+	}
+}
+
+func TestGroupAnagrams(t *testing.T) {
+	cases := []struct {
+		in   []string
+		want [][]string
+	}{
+		{nil, nil},
+		{
+			[]string{"listen", "google", "Silent", "enlist", "gooegl", "cat"},
+			[][]string{{"listen", "Silent", "enlist"}, {"google", "gooegl"}, {"cat"}},
+		},
+		{
+			[]string{"Dormitory", "dirty room!", "Hello, 世界", "界世 olleh"},
+			[][]string{{"Dormitory", "dirty room!"}, {"Hello, 世界", "界世 olleh"}},
+		},
+		{
+			[]string{"e\u0301a", "ae\u0301", "ea"},
+			[][]string{{"e\u0301a", "ae\u0301"}, {"ea"}},
+		},
+	}
+
+	for _, c := range cases {
+		got := GroupAnagrams(c.in)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("GroupAnagrams(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package utilities
+
+import (
+	"encoding/binary"
+	"slices"
+	"unicode/utf8"
+)
+
+// The byte-level functions below never allocate on their own. Since they cannot
+// change the length of the data, runs of invalid UTF-8 bytes are kept as opaque
+// units like ReverseWithOptions does with KeepInvalid, instead of turning into U+FFFD
+
+// AppendReverse appends s reversed rune-wise to dst and returns the extended buffer
+/*
+buf = utilities.AppendReverse(buf[:0], "Hello, 世界") // buf holds "界世 ,olleH"
+*/
+func AppendReverse(dst []byte, s string) []byte {
+	dst = slices.Grow(dst, len(s))
+	out := dst[len(dst) : len(dst)+len(s)]
+	end := len(out)
+	for i := 0; i < len(s); {
+		// ASCII fast path, a single byte goes straight to its mirrored position
+		if c := s[i]; c < utf8.RuneSelf {
+			end--
+			out[end] = c
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && n == 1 {
+			n = invalidRunLen(s[i:])
+		}
+		end -= copy(out[end-n:], s[i:i+n])
+		i += n
+	}
+	return dst[:len(dst)+len(s)]
+}
+
+// ReverseInPlace reverses the UTF-8 encoded b rune-wise without using any extra memory
+/*
+b := []byte("Hello, 世界")
+utilities.ReverseInPlace(b) // b holds "界世 ,olleH"
+*/
+func ReverseInPlace(b []byte) {
+	// Reverse the bytes inside of every multi-byte rune first, reversing the
+	// whole slice afterwards puts them back into the right order
+	for i := 0; i < len(b); {
+		// ASCII fast path, skip eight plain bytes at a time
+		if i+8 <= len(b) && binary.LittleEndian.Uint64(b[i:])&0x8080808080808080 == 0 {
+			i += 8
+			continue
+		}
+		if b[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && n == 1 {
+			// A run of invalid bytes is one unit, like in AppendReverse
+			for i+n < len(b) {
+				if r, size := utf8.DecodeRune(b[i+n:]); r != utf8.RuneError || size != 1 {
+					break
+				}
+				n++
+			}
+		}
+		slices.Reverse(b[i : i+n])
+		i += n
+	}
+	slices.Reverse(b)
+}
@@ -0,0 +1,108 @@
+package utilities
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppendReverse(t *testing.T) {
+	cases := []struct {
+		dst, in, want string
+	}{
+		{"", "Hello, world", "dlrow ,olleH"},
+		{"", "Hello, 世界", "界世 ,olleH"},
+		{"", "", ""},
+		{"prefix:", "abc", "prefix:cba"},
+		{"", "a\xffb\xe4\xb8", "\xe4\xb8b\xffa"},
+		{"", "a\xa9\xc3b", "b\xa9\xc3a"},
+	}
+
+	for _, c := range cases {
+		got := AppendReverse([]byte(c.dst), c.in)
+		if string(got) != c.want {
+			t.Errorf("AppendReverse(%q, %q) == %q, want %q", c.dst, c.in, got, c.want)
+		}
+	}
+}
+
+func TestReverseInPlace(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"Hello, world", "dlrow ,olleH"},
+		{"Hello, 世界", "界世 ,olleH"},
+		{"", ""},
+		{"a long ASCII line that takes the fast path", "htap tsaf eht sekat taht enil IICSA gnol a"},
+		{"ascii first then 世界 and \U0001F600!", "!\U0001F600 dna 界世 neht tsrif iicsa"},
+		{"a\xffb\xe4\xb8", "\xe4\xb8b\xffa"},
+		{"a\xa9\xc3b", "b\xa9\xc3a"},
+	}
+
+	for _, c := range cases {
+		b := []byte(c.in)
+		ReverseInPlace(b)
+		if string(b) != c.want {
+			t.Errorf("ReverseInPlace(%q) == %q, want %q", c.in, b, c.want)
+		}
+	}
+}
+
+// Both functions must not allocate once the buffer is large enough
+func TestReverseBytesAllocations(t *testing.T) {
+	in := strings.Repeat("Hello, 世界 ", 100)
+	buf := make([]byte, 0, len(in))
+	b := []byte(in)
+
+	if n := testing.AllocsPerRun(100, func() { buf = AppendReverse(buf[:0], in) }); n != 0 {
+		t.Errorf("AppendReverse allocates %v times", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { ReverseInPlace(b) }); n != 0 {
+		t.Errorf("ReverseInPlace allocates %v times", n)
+	}
+}
+
+// Run the benchmarks with "go test -bench Reverse -benchmem" to compare them
+// against Reverse, the byte-level functions report 0 allocs/op
+var (
+	benchmarkASCII = strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+	benchmarkMixed = strings.Repeat("Hello, 世界! Grüße \U0001F600 ", 100)
+	benchmarkSink  string
+)
+
+func BenchmarkReverse(b *testing.B) {
+	for _, in := range []struct{ name, s string }{{"ASCII", benchmarkASCII}, {"Mixed", benchmarkMixed}} {
+		b.Run(in.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(in.s)))
+			for b.Loop() {
+				benchmarkSink = Reverse(in.s)
+			}
+		})
+	}
+}
+
+func BenchmarkAppendReverse(b *testing.B) {
+	for _, in := range []struct{ name, s string }{{"ASCII", benchmarkASCII}, {"Mixed", benchmarkMixed}} {
+		b.Run(in.name, func(b *testing.B) {
+			buf := make([]byte, 0, len(in.s))
+			b.ReportAllocs()
+			b.SetBytes(int64(len(in.s)))
+			for b.Loop() {
+				buf = AppendReverse(buf[:0], in.s)
+			}
+		})
+	}
+}
+
+func BenchmarkReverseInPlace(b *testing.B) {
+	for _, in := range []struct{ name, s string }{{"ASCII", benchmarkASCII}, {"Mixed", benchmarkMixed}} {
+		b.Run(in.name, func(b *testing.B) {
+			buf := []byte(in.s)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(in.s)))
+			for b.Loop() {
+				ReverseInPlace(buf)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package utilities
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// How many bytes of the input are read at a time
+const defaultReverseBlockSize = 64 * 1024
+
+// ErrNegativeSize is returned by a ReverseReader built with a negative size
+var ErrNegativeSize = errors.New("utilities: negative size")
+
+// ReadAtSeeker is implemented by *os.File and is what OpenReverseReader needs
+// to find out how large the input is
+type ReadAtSeeker interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// ReverseReader reads its input from the end towards the beginning one block at
+// a time and hands out the content reversed rune-wise, just like Reverse does.
+// Only a single block is held in memory, so files larger than memory work too
+/*
+f, _ := os.Open("huge.log")
+rr, _ := utilities.OpenReverseReader(f)
+io.Copy(os.Stdout, rr)
+*/
+type ReverseReader struct {
+	r   io.ReaderAt
+	pos int64 // Everything before pos has not been read yet
+	err error
+
+	// buf holds the block being reversed, carry the bytes at the start of the
+	// previous block that could belong to a rune beginning before it
+	buf      []byte
+	carryBuf [utf8.UTFMax]byte
+	carry    []byte
+
+	// Reversed output that was not handed out yet
+	outBuf []byte
+	out    []byte
+}
+
+// NewReverseReader returns a ReverseReader over the first size bytes of r
+func NewReverseReader(r io.ReaderAt, size int64) *ReverseReader {
+	return newReverseReaderSize(r, size, defaultReverseBlockSize)
+}
+
+// OpenReverseReader returns a ReverseReader over all of r, the size is found
+// by seeking to the end of r
+func OpenReverseReader(r ReadAtSeeker) (*ReverseReader, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	return NewReverseReader(r, size), nil
+}
+
+func newReverseReaderSize(r io.ReaderAt, size int64, blockSize int) *ReverseReader {
+	rr := &ReverseReader{
+		r:   r,
+		pos: size,
+		buf: make([]byte, blockSize+utf8.UTFMax),
+	}
+	if size < 0 {
+		rr.err = ErrNegativeSize
+	}
+	return rr
+}
+
+// Read implements io.Reader
+func (rr *ReverseReader) Read(p []byte) (int, error) {
+	for len(rr.out) == 0 {
+		if err := rr.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, rr.out)
+	rr.out = rr.out[n:]
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo so that io.Copy skips the extra buffer
+func (rr *ReverseReader) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for {
+		if len(rr.out) == 0 {
+			if err := rr.fill(); err == io.EOF {
+				return written, nil
+			} else if err != nil {
+				return written, err
+			}
+		}
+		n, err := w.Write(rr.out)
+		written += int64(n)
+		rr.out = rr.out[n:]
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// Reads the block in front of pos and reverses it into out
+func (rr *ReverseReader) fill() error {
+	if rr.err != nil {
+		return rr.err
+	}
+	if rr.pos == 0 && len(rr.carry) == 0 {
+		rr.err = io.EOF
+		return rr.err
+	}
+
+	n := int64(len(rr.buf) - utf8.UTFMax)
+	if rr.pos < n {
+		n = rr.pos
+	}
+	if m, err := rr.r.ReadAt(rr.buf[:n], rr.pos-n); int64(m) < n {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		rr.err = err
+		return rr.err
+	}
+	rr.pos -= n
+	chunk := append(rr.buf[:n], rr.carry...)
+
+	// Continuation bytes at the start of the chunk may finish a rune that
+	// begins in the block before, keep them around until that one is read
+	head := 0
+	if rr.pos > 0 {
+		for head < len(chunk) && head < utf8.UTFMax-1 && !utf8.RuneStart(chunk[head]) {
+			head++
+		}
+	}
+
+	rr.outBuf = rr.outBuf[:0]
+	for end := len(chunk); end > head; {
+		r, size := utf8.DecodeLastRune(chunk[head:end])
+		rr.outBuf = utf8.AppendRune(rr.outBuf, r)
+		end -= size
+	}
+	rr.out = rr.outBuf
+	rr.carry = append(rr.carryBuf[:0], chunk[:head]...)
+	return nil
+}
@@ -0,0 +1,98 @@
+package utilities
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReverseReader(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"Hello, world", "dlrow ,olleH"},
+		{"Hello, 世界", "界世 ,olleH"},
+		{"", ""},
+		{"\U0001F600 x \U0001F601", "\U0001F601 x \U0001F600"},
+		// Invalid bytes come out as U+FFFD the same way Reverse does it
+		{"a\xffb", "b�a"},
+		{"\xe4\xb8x", "x��"},
+		{"\x80\x80\x80\x80\x80", strings.Repeat("�", 5)},
+	}
+
+	// Small blocks make runes cross the block boundaries at every position
+	for _, blockSize := range []int{1, 2, 3, 4, 5, defaultReverseBlockSize} {
+		for _, c := range cases {
+			rr := newReverseReaderSize(strings.NewReader(c.in), int64(len(c.in)), blockSize)
+			got, err := io.ReadAll(rr)
+			if err != nil {
+				t.Fatalf("block %d: reading %q: %v", blockSize, c.in, err)
+			}
+			if string(got) != c.want {
+				t.Errorf("block %d: ReverseReader(%q) == %q, want %q", blockSize, c.in, got, c.want)
+			}
+		}
+	}
+}
+
+// Random mixes of ASCII, multi-byte runes and invalid bytes must come out
+// exactly as Reverse returns them
+func TestReverseReaderMatchesReverse(t *testing.T) {
+	pieces := []string{"a", "Z", " ", "é", "世", "\U0001F600", "\xff", "\x80", "\xe4\xb8", "\xf0\x9f"}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var b strings.Builder
+		for n := rng.Intn(40); n > 0; n-- {
+			b.WriteString(pieces[rng.Intn(len(pieces))])
+		}
+		in := b.String()
+
+		var out bytes.Buffer
+		rr := newReverseReaderSize(strings.NewReader(in), int64(len(in)), 1+rng.Intn(8))
+		if _, err := io.Copy(&out, rr); err != nil {
+			t.Fatal(err)
+		}
+		if want := Reverse(in); out.String() != want {
+			t.Fatalf("ReverseReader(%q) == %q, want %q", in, out.String(), want)
+		}
+	}
+}
+
+func TestOpenReverseReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.log")
+	in := strings.Repeat("line 世界\n", 20000)
+	if err := os.WriteFile(path, []byte(in), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rr, err := OpenReverseReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iotest.TestReader(rr, []byte(Reverse(in))); err != nil {
+		t.Error(err)
+	}
+}
+
+// A reader that is shorter than the size it was given must report an error
+func TestReverseReaderShortInput(t *testing.T) {
+	rr := NewReverseReader(strings.NewReader("abc"), 10)
+	if _, err := io.ReadAll(rr); err != io.ErrUnexpectedEOF {
+		t.Errorf("err == %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	rr = NewReverseReader(strings.NewReader("abc"), -1)
+	if _, err := io.ReadAll(rr); err != ErrNegativeSize {
+		t.Errorf("err == %v, want %v", err, ErrNegativeSize)
+	}
+}
@@ -1,6 +1,13 @@
 // Package utilities defines the name of the package that is going to be the container for these methods
 package utilities
 
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 // Reverse and returns its argument string reversed rune-wise left to right
 /*
 Takes in as input an argument of type string and returns a string
@@ -10,9 +17,197 @@ func Reverse(inputString string) string {
 	// The *rune* key is able to interchange between int32 and character values
 	stringRune := []rune(inputString)
 	// Reverse the array
-	for i, j := 0, len(stringRune)-1; i < len(stringRune)/2; i, j = i+1, j-1 {
-		stringRune[i], stringRune[j] = stringRune[j], stringRune[i]
-	}
+	ReverseSlice(stringRune)
 
 	return string(stringRune)
 }
+
+// InvalidUTF8Policy tells ReverseWithOptions what to do with bytes that are not valid UTF-8
+type InvalidUTF8Policy int
+
+const (
+	// ReplaceInvalid turns every invalid byte into U+FFFD, the same thing Reverse does
+	ReplaceInvalid InvalidUTF8Policy = iota
+	// KeepInvalid moves every run of invalid bytes as one opaque unit that
+	// keeps its bytes in their original order, so reversing the result again
+	// gives back the original bytes
+	KeepInvalid
+	// FailOnInvalid stops at the first invalid byte and returns an *InvalidUTF8Error
+	FailOnInvalid
+)
+
+// ReverseOptions changes how ReverseWithOptions treats its input, the zero
+// value behaves like Reverse
+type ReverseOptions struct {
+	Invalid InvalidUTF8Policy
+	// Form normalizes the text before it is reversed, so that composed and
+	// decomposed input give the same result. The zero value leaves it as is
+	Form Form
+}
+
+// InvalidUTF8Error is returned by ReverseWithOptions under FailOnInvalid
+type InvalidUTF8Error struct {
+	// Offset is the byte offset of the first invalid sequence in the input
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("utilities: invalid UTF-8 at byte offset %d", e.Offset)
+}
+
+// ReverseWithOptions returns its argument string reversed rune-wise like Reverse,
+// but lets the caller pick what happens to invalid UTF-8
+/*
+reversed, err := utilities.ReverseWithOptions(s, utilities.ReverseOptions{Invalid: utilities.FailOnInvalid})
+*/
+func ReverseWithOptions(inputString string, opts ReverseOptions) (string, error) {
+	if opts.Form != 0 {
+		// The offset in the error refers to the text as it was given
+		if opts.Invalid == FailOnInvalid && !utf8.ValidString(inputString) {
+			_, err := ReverseWithOptions(inputString, ReverseOptions{Invalid: FailOnInvalid})
+			return "", err
+		}
+		// Invalid bytes come out of Normalize unchanged
+		inputString = Normalize(opts.Form, inputString)
+	}
+
+	// The first pass finds out how long the result is going to be
+	size := len(inputString)
+	for i := 0; i < len(inputString); {
+		r, n := utf8.DecodeRuneInString(inputString[i:])
+		if r == utf8.RuneError && n == 1 {
+			switch opts.Invalid {
+			case FailOnInvalid:
+				return "", &InvalidUTF8Error{Offset: i}
+			case ReplaceInvalid:
+				size += utf8.RuneLen(utf8.RuneError) - 1
+			}
+		}
+		i += n
+	}
+
+	// The second pass copies every unit to the mirrored position
+	out := make([]byte, size)
+	end := size
+	for i := 0; i < len(inputString); {
+		r, n := utf8.DecodeRuneInString(inputString[i:])
+		if r == utf8.RuneError && n == 1 && opts.Invalid == KeepInvalid {
+			// Moving the bytes one at a time could turn them into a valid
+			// rune, "\xa9\xc3" would come out as "é"
+			n = invalidRunLen(inputString[i:])
+		}
+		unit := inputString[i : i+n]
+		if r == utf8.RuneError && n == 1 && opts.Invalid == ReplaceInvalid {
+			unit = "\uFFFD"
+		}
+		end -= copy(out[end-len(unit):], unit)
+		i += n
+	}
+	return string(out), nil
+}
+
+// Returns the length of the run of invalid bytes at the start of s
+func invalidRunLen(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// ReverseWords reverses the order of the words in its argument string. A word is
+// a run of non-space runes, so punctuation stays attached to its word, and the
+// runs of white space stay where they are
+/*
+ReverseWords("  Hello,  world!\n") == "  world!  Hello,\n"
+*/
+func ReverseWords(inputString string) string {
+	return reverseTokens(inputString, nextWord)
+}
+
+// ReverseLines reverses the order of the lines in its argument string. Every line
+// keeps its own "\n" or "\r\n" terminator, and empty lines are lines like any
+// other. A last line without a terminator gets the one of the line before it, so
+// that it does not run into the next line once it is moved
+/*
+ReverseLines("a\nb\r\nc\r\n") == "c\r\nb\r\na\n"
+ReverseLines("a\nb\r\nc") == "c\r\nb\r\na\n"
+ReverseLines("\nb") == "b\n\n"
+*/
+func ReverseLines(inputString string) string {
+	var lines []string
+	for rest := inputString; rest != ""; {
+		i := strings.IndexByte(rest, '\n') + 1
+		if i == 0 {
+			i = len(rest)
+		}
+		lines = append(lines, rest[:i])
+		rest = rest[i:]
+	}
+	if len(lines) < 2 {
+		return inputString
+	}
+
+	last := len(lines) - 1
+	if !strings.HasSuffix(lines[last], "\n") {
+		lines[last] += lineTerminator(lines[last-1])
+	}
+
+	var b strings.Builder
+	b.Grow(len(inputString) + 2)
+	for i := last; i >= 0; i-- {
+		b.WriteString(lines[i])
+	}
+	return b.String()
+}
+
+// Returns the "\n" or "\r\n" at the end of line
+func lineTerminator(line string) string {
+	if strings.HasSuffix(line, "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Splits the input into tokens with next and writes them back in reverse order,
+// the separators between the tokens are written at their original positions
+func reverseTokens(inputString string, next func(s string) (start, end int)) string {
+	var tokens, separators []string
+	rest := inputString
+	for {
+		start, end := next(rest)
+		separators = append(separators, rest[:start])
+		if start == end {
+			break
+		}
+		tokens = append(tokens, rest[start:end])
+		rest = rest[end:]
+	}
+
+	var b strings.Builder
+	b.Grow(len(inputString))
+	for i, separator := range separators {
+		b.WriteString(separator)
+		if i < len(tokens) {
+			b.WriteString(tokens[len(tokens)-1-i])
+		}
+	}
+	return b.String()
+}
+
+// Finds the first run of non-space runes in s
+func nextWord(s string) (start, end int) {
+	start = strings.IndexFunc(s, func(r rune) bool { return !unicode.IsSpace(r) })
+	if start < 0 {
+		return len(s), len(s)
+	}
+	end = strings.IndexFunc(s[start:], unicode.IsSpace)
+	if end < 0 {
+		return start, len(s)
+	}
+	return start, start + end
+}
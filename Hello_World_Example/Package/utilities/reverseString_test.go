@@ -26,3 +26,107 @@ func TestReverse(t *testing.T) {
 		}
 	}
 }
+
+func TestReverseWithOptions(t *testing.T) {
+	cases := []struct {
+		in     string
+		policy InvalidUTF8Policy
+		want   string
+		offset int // Byte offset the error should report, -1 for no error
+	}{
+		{"Hello, 世界", ReplaceInvalid, "界世 ,olleH", -1},
+		{"Hello, 世界", KeepInvalid, "界世 ,olleH", -1},
+		{"Hello, 世界", FailOnInvalid, "界世 ,olleH", -1},
+		{"", FailOnInvalid, "", -1},
+		{"a\xffb", ReplaceInvalid, "b�a", -1},
+		{"a\xffb", KeepInvalid, "b\xffa", -1},
+		{"a\xffb", FailOnInvalid, "", 1},
+		{"世\xe4\xb8", ReplaceInvalid, "��世", -1},
+		{"世\xe4\xb8", KeepInvalid, "\xe4\xb8世", -1},
+		// Adjacent invalid bytes stay in their order instead of forming "é"
+		{"a\xa9\xc3b", KeepInvalid, "b\xa9\xc3a", -1},
+		{"a\xa9\xc3b", ReplaceInvalid, "b��a", -1},
+		{"世\xe4\xb8", FailOnInvalid, "", 3},
+	}
+
+	for _, c := range cases {
+		got, err := ReverseWithOptions(c.in, ReverseOptions{Invalid: c.policy})
+		if c.offset >= 0 {
+			invalid, ok := err.(*InvalidUTF8Error)
+			if !ok || invalid.Offset != c.offset {
+				t.Errorf("ReverseWithOptions(%q, %d) error == %v, want offset %d", c.in, c.policy, err, c.offset)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("ReverseWithOptions(%q, %d) == %q, %v, want %q", c.in, c.policy, got, err, c.want)
+		}
+	}
+}
+
+// Reversing twice while keeping invalid bytes must give back the input
+func TestReverseWithOptionsKeepRoundTrip(t *testing.T) {
+	for _, in := range []string{"\xff\xfe\x00binary\x80", "é\xc3", "\xa9\xc3", "\U0001F600\xf0\x9f\x98"} {
+		once, _ := ReverseWithOptions(in, ReverseOptions{Invalid: KeepInvalid})
+		twice, _ := ReverseWithOptions(once, ReverseOptions{Invalid: KeepInvalid})
+		if twice != in {
+			t.Errorf("reversing %q twice == %q", in, twice)
+		}
+		if replaced, _ := ReverseWithOptions(in, ReverseOptions{}); replaced != Reverse(in) {
+			t.Errorf("ReverseWithOptions(%q) == %q, want %q", in, replaced, Reverse(in))
+		}
+	}
+}
+
+func TestReverseWords(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"Hello, world", "world Hello,"},
+		{"Hello, 世界", "世界 Hello,"},
+		{"", ""},
+		{"   ", "   "},
+		{"one", "one"},
+		{"  Hello,  world!\n", "  world!  Hello,\n"},
+		{"a\tb  c　d", "d\tc  b　a"},
+		{"(quoted) \"words\" stay.", "stay. \"words\" (quoted)"},
+	}
+
+	for _, c := range cases {
+		got := ReverseWords(c.in)
+		if got != c.want {
+			t.Errorf("ReverseWords(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestReverseLines(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"Hello, world", "Hello, world"},
+		{"", ""},
+		{"\n", "\n"},
+		{"a\nb\nc\n", "c\nb\na\n"},
+		{"a\r\nb\r\n", "b\r\na\r\n"},
+		{"a\nb\r\nc\r\n", "c\r\nb\r\na\n"},
+		// A last line without a terminator gets the one of the line before it
+		{"a\nb\nc", "c\nb\na\n"},
+		{"a\r\nb", "b\r\na\r\n"},
+		{"a\nb\r\nc", "c\r\nb\r\na\n"},
+		{"a\r\nb\nc", "c\nb\na\r\n"},
+		// Empty lines are kept
+		{"\nb", "b\n\n"},
+		{"a\n\n", "\na\n"},
+		{"a\n\nb\n", "b\n\na\n"},
+		{"\n\r\n", "\r\n\n"},
+		{"Hello,\n世界\n", "世界\nHello,\n"},
+	}
+
+	for _, c := range cases {
+		got := ReverseLines(c.in)
+		if got != c.want {
+			t.Errorf("ReverseLines(%q) == %q, want %q", c.in, got, c.want)
+		}
+	}
+}
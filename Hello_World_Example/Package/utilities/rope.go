@@ -0,0 +1,514 @@
+package utilities
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// The most bytes a leaf of a rope holds
+const ropeLeafSize = 1024
+
+// Rope is a string stored as a balanced tree of short pieces, so that large
+// texts can be edited without copying them. Concat, Split, Insert, Delete and
+// RuneAt take O(log n) time. A Rope is never changed, every edit returns a new
+// Rope that shares most of its tree with the old one, which makes undo cheap
+// and lets many goroutines read the same Rope. The zero value is the empty text.
+// Positions are rune indexes into the text as a whole, a byte that is not valid
+// UTF-8 counts as a rune of its own and reads as U+FFFD. Putting together two
+// pieces of a rune, such as "\xe4" and "\xb8\x96", gives the rune "世"
+/*
+r := utilities.NewRope("Hello, world")
+r = r.Insert(7, "big ").Delete(0, 7)
+r.String() == "big world"
+*/
+type Rope struct {
+	root *ropeNode
+}
+
+// A leaf holds a piece of the text, the other nodes always have two children
+type ropeNode struct {
+	left, right *ropeNode
+	leaf        string
+	bytes       int
+	runes       int
+	height      int // 0 for a leaf
+}
+
+func newRopeLeaf(s string) *ropeNode {
+	if s == "" {
+		return nil
+	}
+	return &ropeNode{leaf: s, bytes: len(s), runes: utf8.RuneCountInString(s)}
+}
+
+func newRopeNode(left, right *ropeNode) *ropeNode {
+	return &ropeNode{
+		left:   left,
+		right:  right,
+		bytes:  left.bytes + right.bytes,
+		runes:  left.runes + right.runes,
+		height: 1 + max(left.height, right.height),
+	}
+}
+
+// NewRope returns a Rope holding s
+func NewRope(s string) Rope {
+	return Rope{ropeBuild(ropeLeaves(s))}
+}
+
+// Cuts s into pieces of at most ropeLeafSize bytes
+func ropeLeaves(s string) []string {
+	var leaves []string
+	for len(s) > ropeLeafSize {
+		// Never cut a rune in two
+		cut := ropeLeafSize
+		for cut > ropeLeafSize-utf8.UTFMax && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		leaves = append(leaves, s[:cut])
+		s = s[cut:]
+	}
+	if s != "" {
+		leaves = append(leaves, s)
+	}
+	return leaves
+}
+
+// ReadRope returns a Rope holding everything r has to read
+func ReadRope(r io.Reader) (Rope, error) {
+	var leaves []string
+	buf := make([]byte, 64*1024)
+	carry := 0 // Bytes of a rune cut off by the end of the previous read
+	for {
+		n, err := r.Read(buf[carry:])
+		n += carry
+		// Hold back a rune that is not complete yet, unless it never will be
+		cut := n
+		for i := n - 1; i >= 0 && i >= n-utf8.UTFMax; i-- {
+			if utf8.RuneStart(buf[i]) {
+				if !utf8.FullRune(buf[i:n]) && err == nil {
+					cut = i
+				}
+				break
+			}
+		}
+		leaves = append(leaves, ropeLeaves(string(buf[:cut]))...)
+		carry = copy(buf, buf[cut:n])
+		if err == io.EOF {
+			return Rope{ropeBuild(leaves)}, nil
+		}
+		if err != nil {
+			return Rope{}, err
+		}
+	}
+}
+
+// Builds a balanced tree out of leaves
+func ropeBuild(leaves []string) *ropeNode {
+	switch len(leaves) {
+	case 0:
+		return nil
+	case 1:
+		return newRopeLeaf(leaves[0])
+	}
+	mid := len(leaves) / 2
+	return newRopeNode(ropeBuild(leaves[:mid]), ropeBuild(leaves[mid:]))
+}
+
+// Puts left and right together with AVL rotations when one is more than one
+// level higher than the other, both have to be balanced already
+func ropeBalanced(left, right *ropeNode) *ropeNode {
+	switch {
+	case left.height > right.height+1:
+		if left.left.height >= left.right.height {
+			return newRopeNode(left.left, newRopeNode(left.right, right))
+		}
+		return newRopeNode(
+			newRopeNode(left.left, left.right.left),
+			newRopeNode(left.right.right, right),
+		)
+	case right.height > left.height+1:
+		if right.right.height >= right.left.height {
+			return newRopeNode(newRopeNode(left, right.left), right.right)
+		}
+		return newRopeNode(
+			newRopeNode(left, right.left.left),
+			newRopeNode(right.left.right, right.right),
+		)
+	}
+	return newRopeNode(left, right)
+}
+
+// Joins two balanced trees into one, in time proportional to the difference
+// of their heights. Short leaves that meet are merged into a single one. The
+// text of left and right must not end and start with the pieces of a rune, see
+// ropeConcat
+func ropeJoin(left, right *ropeNode) *ropeNode {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.height == 0 && right.height == 0 && left.bytes+right.bytes <= ropeLeafSize:
+		return newRopeLeaf(left.leaf + right.leaf)
+	case left.height > right.height+1:
+		return ropeBalanced(left.left, ropeJoin(left.right, right))
+	case right.height > left.height+1:
+		return ropeBalanced(ropeJoin(left, right.left), right.right)
+	}
+	return newRopeNode(left, right)
+}
+
+// Joins left and right like ropeJoin. When left ends with the first bytes of
+// a rune and right starts with the rest of it, those bytes are put together in
+// a leaf of their own first, so that the runes of every leaf are the runes of
+// the text. Otherwise the rune would count as the several runes it was before
+func ropeConcat(left, right *ropeNode) *ropeNode {
+	if left == nil || right == nil {
+		return ropeJoin(left, right)
+	}
+	// An incomplete rune has at most UTFMax-1 bytes
+	tail := ropeSuffix(left, utf8.UTFMax-1)
+	start := -1
+	for i := len(tail) - 1; i >= 0; i-- {
+		if utf8.RuneStart(tail[i]) {
+			if !utf8.FullRuneInString(tail[i:]) {
+				start = i
+			}
+			break
+		}
+	}
+	if start < 0 {
+		return ropeJoin(left, right)
+	}
+	tail = tail[start:]
+	head := ropePrefix(right, utf8.UTFMax-1)
+	_, size := utf8.DecodeRuneInString(tail + head)
+	if size <= len(tail) {
+		return ropeJoin(left, right)
+	}
+	head = head[:size-len(tail)]
+	left, _ = ropeSplitBytes(left, left.bytes-len(tail))
+	_, right = ropeSplitBytes(right, len(head))
+	return ropeJoin(ropeJoin(left, newRopeLeaf(tail+head)), right)
+}
+
+// Returns the last n bytes of the text below node, all of it when it is shorter
+func ropeSuffix(node *ropeNode, n int) string {
+	s := ""
+	node.walk(true, func(leaf string) bool {
+		s = leaf[max(len(leaf)-(n-len(s)), 0):] + s
+		return len(s) < n
+	})
+	return s
+}
+
+// Returns the first n bytes of the text below node, all of it when it is shorter
+func ropePrefix(node *ropeNode, n int) string {
+	s := ""
+	node.walk(false, func(leaf string) bool {
+		s += leaf[:min(n-len(s), len(leaf))]
+		return len(s) < n
+	})
+	return s
+}
+
+// Splits n into its first b bytes and the rest. Unlike ropeSplit it may cut a
+// rune in two, ropeConcat only cuts between the pieces of an incomplete one
+func ropeSplitBytes(n *ropeNode, b int) (*ropeNode, *ropeNode) {
+	switch {
+	case n == nil || b <= 0:
+		return nil, n
+	case b >= n.bytes:
+		return n, nil
+	case n.height == 0:
+		return newRopeLeaf(n.leaf[:b]), newRopeLeaf(n.leaf[b:])
+	case b <= n.left.bytes:
+		left, right := ropeSplitBytes(n.left, b)
+		return left, ropeJoin(right, n.right)
+	}
+	left, right := ropeSplitBytes(n.right, b-n.left.bytes)
+	return ropeJoin(n.left, left), right
+}
+
+// Splits n into its first i runes and the rest
+func ropeSplit(n *ropeNode, i int) (*ropeNode, *ropeNode) {
+	switch {
+	case n == nil || i <= 0:
+		return nil, n
+	case i >= n.runes:
+		return n, nil
+	case n.height == 0:
+		b := runeOffset(n.leaf, i)
+		return newRopeLeaf(n.leaf[:b]), newRopeLeaf(n.leaf[b:])
+	case i <= n.left.runes:
+		left, right := ropeSplit(n.left, i)
+		return left, ropeJoin(right, n.right)
+	}
+	left, right := ropeSplit(n.right, i-n.left.runes)
+	return ropeJoin(n.left, left), right
+}
+
+// Returns the byte offset of rune i in s
+func runeOffset(s string, i int) int {
+	for b := range s {
+		if i == 0 {
+			return b
+		}
+		i--
+	}
+	return len(s)
+}
+
+func (r Rope) checkIndex(i, limit int) {
+	if i < 0 || i > limit {
+		panic(fmt.Sprintf("utilities: rope index %d out of range [0:%d]", i, limit))
+	}
+}
+
+// Len returns the length of r in bytes
+func (r Rope) Len() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.bytes
+}
+
+// RuneLen returns the number of runes in r
+func (r Rope) RuneLen() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.runes
+}
+
+// String returns the text of r
+func (r Rope) String() string {
+	var b strings.Builder
+	b.Grow(r.Len())
+	for chunk := range r.Chunks() {
+		b.WriteString(chunk)
+	}
+	return b.String()
+}
+
+// Concat returns r followed by other. When r ends with the first bytes of a
+// rune and other starts with the rest, they are one rune in the result
+func (r Rope) Concat(other Rope) Rope {
+	return Rope{ropeConcat(r.root, other.root)}
+}
+
+// Split returns the first i runes of r and the rest, it panics when i is not
+// between 0 and RuneLen
+func (r Rope) Split(i int) (Rope, Rope) {
+	r.checkIndex(i, r.RuneLen())
+	left, right := ropeSplit(r.root, i)
+	return Rope{left}, Rope{right}
+}
+
+// Insert returns r with s put in front of rune i
+func (r Rope) Insert(i int, s string) Rope {
+	left, right := r.Split(i)
+	return left.Concat(NewRope(s)).Concat(right)
+}
+
+// Delete returns r without the runes from i up to j
+func (r Rope) Delete(i, j int) Rope {
+	r.checkIndex(j, r.RuneLen())
+	r.checkIndex(i, j)
+	left, rest := r.Split(i)
+	_, right := rest.Split(j - i)
+	return left.Concat(right)
+}
+
+// Slice returns the runes of r from i up to j
+func (r Rope) Slice(i, j int) Rope {
+	r.checkIndex(j, r.RuneLen())
+	r.checkIndex(i, j)
+	_, rest := r.Split(i)
+	middle, _ := rest.Split(j - i)
+	return middle
+}
+
+// RuneAt returns rune i of r, it panics when i is out of range
+func (r Rope) RuneAt(i int) rune {
+	if i < 0 || i >= r.RuneLen() {
+		panic(fmt.Sprintf("utilities: rope index %d out of range [0:%d]", i, r.RuneLen()))
+	}
+	n := r.root
+	for n.height > 0 {
+		if i < n.left.runes {
+			n = n.left
+		} else {
+			i -= n.left.runes
+			n = n.right
+		}
+	}
+	c, _ := utf8.DecodeRuneInString(n.leaf[runeOffset(n.leaf, i):])
+	return c
+}
+
+// Chunks iterates over the pieces r is made of, in order. Putting them together
+// gives the text of r
+func (r Rope) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		r.root.walk(false, yield)
+	}
+}
+
+// Runes iterates over the runes of r together with their index
+func (r Rope) Runes() iter.Seq2[int, rune] {
+	return func(yield func(int, rune) bool) {
+		i := 0
+		for chunk := range r.Chunks() {
+			for _, c := range chunk {
+				if !yield(i, c) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// Calls yield with every leaf below n, from the last one when backwards is set
+func (n *ropeNode) walk(backwards bool, yield func(string) bool) bool {
+	switch {
+	case n == nil:
+		return true
+	case n.height == 0:
+		return yield(n.leaf)
+	case backwards:
+		return n.right.walk(backwards, yield) && n.left.walk(backwards, yield)
+	}
+	return n.left.walk(backwards, yield) && n.right.walk(backwards, yield)
+}
+
+// Reader returns an io.Reader over the text of r
+func (r Rope) Reader() io.Reader {
+	return newRopeReader(r.root, false)
+}
+
+// WriteTo writes the text of r to w, it lets io.Copy skip the Reader
+func (r Rope) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for chunk := range r.Chunks() {
+		n, err := io.WriteString(w, chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Reads the leaves of a tree one after the other, walking it with a stack
+type ropeReader struct {
+	stack     []*ropeNode
+	chunk     string
+	backwards bool
+}
+
+func newRopeReader(root *ropeNode, backwards bool) *ropeReader {
+	rr := &ropeReader{backwards: backwards}
+	if root != nil {
+		rr.stack = []*ropeNode{root}
+	}
+	return rr
+}
+
+func (rr *ropeReader) Read(p []byte) (int, error) {
+	for rr.chunk == "" {
+		if len(rr.stack) == 0 {
+			return 0, io.EOF
+		}
+		n := rr.stack[len(rr.stack)-1]
+		rr.stack = rr.stack[:len(rr.stack)-1]
+		for n.height > 0 {
+			if rr.backwards {
+				rr.stack = append(rr.stack, n.left)
+				n = n.right
+			} else {
+				rr.stack = append(rr.stack, n.right)
+				n = n.left
+			}
+		}
+		rr.chunk = n.leaf
+		if rr.backwards {
+			// Only this leaf is copied
+			rr.chunk = Reverse(n.leaf)
+		}
+	}
+	n := copy(p, rr.chunk)
+	rr.chunk = rr.chunk[n:]
+	return n, nil
+}
+
+// ReversedRope is a view of a Rope with its runes in reverse order, the text is
+// not copied to make it. It reads the same as Reverse of the text of the Rope
+/*
+v := utilities.NewRope("Hello, 世界").Reversed()
+v.String() == "界世 ,olleH"
+*/
+type ReversedRope struct {
+	rope Rope
+}
+
+// Reversed returns a view of r that reads it backwards
+func (r Rope) Reversed() ReversedRope {
+	return ReversedRope{r}
+}
+
+// Rope returns the Rope v is a view of
+func (v ReversedRope) Rope() Rope {
+	return v.rope
+}
+
+// RuneLen returns the number of runes in v
+func (v ReversedRope) RuneLen() int {
+	return v.rope.RuneLen()
+}
+
+// RuneAt returns rune i of v, which is rune RuneLen-1-i of the Rope
+func (v ReversedRope) RuneAt(i int) rune {
+	if i < 0 || i >= v.RuneLen() {
+		panic(fmt.Sprintf("utilities: rope index %d out of range [0:%d]", i, v.RuneLen()))
+	}
+	return v.rope.RuneAt(v.RuneLen() - 1 - i)
+}
+
+// Runes iterates over the runes of v together with their index in v
+func (v ReversedRope) Runes() iter.Seq2[int, rune] {
+	return func(yield func(int, rune) bool) {
+		i := 0
+		v.rope.root.walk(true, func(leaf string) bool {
+			for len(leaf) > 0 {
+				c, size := utf8.DecodeLastRuneInString(leaf)
+				if !yield(i, c) {
+					return false
+				}
+				leaf = leaf[:len(leaf)-size]
+				i++
+			}
+			return true
+		})
+	}
+}
+
+// String returns the reversed text
+func (v ReversedRope) String() string {
+	var b strings.Builder
+	b.Grow(v.rope.Len())
+	for _, c := range v.Runes() {
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Reader returns an io.Reader over the reversed text
+func (v ReversedRope) Reader() io.Reader {
+	return newRopeReader(v.rope.root, true)
+}
@@ -0,0 +1,205 @@
+package utilities
+
+import (
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+)
+
+// Checks that every node of the tree is balanced and knows its size
+func checkRope(t *testing.T, n *ropeNode) {
+	t.Helper()
+	if n == nil || n.height == 0 {
+		return
+	}
+	if d := n.left.height - n.right.height; d < -1 || d > 1 {
+		t.Fatalf("Rope node with children of heights %d and %d", n.left.height, n.right.height)
+	}
+	if n.runes != n.left.runes+n.right.runes || n.bytes != n.left.bytes+n.right.bytes {
+		t.Fatalf("Rope node with the wrong size")
+	}
+	checkRope(t, n.left)
+	checkRope(t, n.right)
+}
+
+func TestRopeEdits(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	pieces := []string{"Hello", ", ", "世界", "é", "\U0001F600", strings.Repeat("ab", 700)}
+	r := NewRope(strings.Repeat("The quick brown fox. ", 200))
+	want := []rune(r.String())
+
+	for step := 0; step < 2000; step++ {
+		i := rng.Intn(len(want) + 1)
+		switch rng.Intn(4) {
+		case 0, 1:
+			s := pieces[rng.Intn(len(pieces))]
+			r = r.Insert(i, s)
+			want = append(want[:i:i], append([]rune(s), want[i:]...)...)
+		case 2:
+			j := i + rng.Intn(min(len(want)-i, 3000)+1)
+			r = r.Delete(i, j)
+			want = append(want[:i:i], want[j:]...)
+		case 3:
+			left, right := r.Split(i)
+			if left.RuneLen() != i || right.RuneLen() != len(want)-i {
+				t.Fatalf("Split(%d) == %d and %d runes", i, left.RuneLen(), right.RuneLen())
+			}
+			// Put back together the other way around
+			r = right.Concat(left)
+			want = append(want[i:len(want):len(want)], want[:i]...)
+		}
+		checkRope(t, r.root)
+		if r.RuneLen() != len(want) {
+			t.Fatalf("Step %d: RuneLen() == %d, want %d", step, r.RuneLen(), len(want))
+		}
+		if len(want) > 0 {
+			k := rng.Intn(len(want))
+			if got := r.RuneAt(k); got != want[k] {
+				t.Fatalf("Step %d: RuneAt(%d) == %q, want %q", step, k, got, want[k])
+			}
+		}
+	}
+	if r.String() != string(want) {
+		t.Errorf("The rope does not hold the edited text")
+	}
+	if r.Len() != len(string(want)) {
+		t.Errorf("Len() == %d, want %d", r.Len(), len(string(want)))
+	}
+}
+
+func TestRopeSlice(t *testing.T) {
+	r := NewRope("Hello, ").Concat(NewRope("世界!"))
+	cases := []struct {
+		i, j int
+		want string
+	}{
+		{0, 5, "Hello"},
+		{7, 9, "世界"},
+		{3, 3, ""},
+		{0, 10, "Hello, 世界!"},
+	}
+
+	for _, c := range cases {
+		if got := r.Slice(c.i, c.j).String(); got != c.want {
+			t.Errorf("Slice(%d, %d) == %q, want %q", c.i, c.j, got, c.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Slice(5, 11) did not panic")
+		}
+	}()
+	r.Slice(5, 11)
+}
+
+// The pieces of a rune become the rune when they meet, whatever leaves they
+// are in, so the positions always match the text
+func TestRopeInvalidUTF8(t *testing.T) {
+	cases := []struct {
+		rope Rope
+		want string
+	}{
+		{NewRope("\xe4").Concat(NewRope("\xb8\x96")), "世"},
+		{NewRope("\xe4\xb8").Concat(NewRope("\x96")), "世"},
+		{NewRope("a\xf0").Concat(NewRope("\x9f")).Concat(NewRope("\x98\x80b")), "a\U0001F600b"},
+		{NewRope("\xe4\x96").Insert(1, "\xb8"), "世"},
+		{NewRope("\xe4a\xb8\x96").Delete(1, 2), "世"},
+		// Pieces that do not make a rune stay runes of their own
+		{NewRope("\xe4").Concat(NewRope("a\x96")), "\xe4a\x96"},
+		{NewRope("\xe4\xb8").Concat(NewRope("\xe4")), "\xe4\xb8\xe4"},
+	}
+
+	for _, c := range cases {
+		r := c.rope
+		checkRope(t, r.root)
+		if r.String() != c.want || r.RuneLen() != utf8.RuneCountInString(c.want) {
+			t.Errorf("Rope %q has %d runes, want %q with %d", r.String(), r.RuneLen(), c.want, utf8.RuneCountInString(c.want))
+		}
+		for i, want := range []rune(c.want) {
+			if got := r.RuneAt(i); got != want {
+				t.Errorf("Rope %q: RuneAt(%d) == %q, want %q", c.want, i, got, want)
+			}
+		}
+		if got := r.Reversed().String(); got != Reverse(c.want) {
+			t.Errorf("Rope %q: Reversed() == %q, want %q", c.want, got, Reverse(c.want))
+		}
+	}
+
+	// Bytes of runes put in at random places
+	rng := rand.New(rand.NewSource(1))
+	pieces := []string{"\xe4", "\xb8", "\x96", "\xf0\x9f", "\x98\x80", "a", "世"}
+	var r Rope
+	text := ""
+	for range 2000 {
+		s := pieces[rng.Intn(len(pieces))]
+		i := rng.Intn(r.RuneLen() + 1)
+		b := runeOffset(text, i)
+		r, text = r.Insert(i, s), text[:b]+s+text[b:]
+		checkRope(t, r.root)
+		if r.RuneLen() != utf8.RuneCountInString(text) {
+			t.Fatalf("Rope has %d runes, its text %d", r.RuneLen(), utf8.RuneCountInString(text))
+		}
+	}
+	if r.String() != text {
+		t.Errorf("The rope does not hold the text")
+	}
+}
+
+func TestRopeReversed(t *testing.T) {
+	cases := []string{
+		"",
+		"Hello, 世界",
+		strings.Repeat("añb€\U0001F600", 1000),
+		"bad \xff bytes",
+	}
+
+	for _, in := range cases {
+		v := NewRope(in).Reversed()
+		want := Reverse(in)
+		if got := v.String(); got != want {
+			t.Errorf("NewRope(%.20q).Reversed().String() == %.20q, want %.20q", in, got, want)
+		}
+		got, err := io.ReadAll(v.Reader())
+		if err != nil || string(got) != want {
+			t.Errorf("Reading NewRope(%.20q).Reversed() == %.20q, %v, want %.20q", in, got, err, want)
+		}
+		runes := []rune(want)
+		for i, c := range v.Runes() {
+			if c != runes[i] || v.RuneAt(i) != runes[i] {
+				t.Errorf("Rune %d of NewRope(%.20q).Reversed() is wrong", i, in)
+				break
+			}
+		}
+	}
+}
+
+func TestRopeReaders(t *testing.T) {
+	in := strings.Repeat("Hello, 世界 \U0001F600 ", 10000)
+	// A byte at a time splits runes between reads
+	r, err := ReadRope(iotest.OneByteReader(strings.NewReader(in)))
+	if err != nil || r.String() != in {
+		t.Fatalf("ReadRope did not give back the text, %v", err)
+	}
+	if r.RuneLen() != len([]rune(in)) {
+		t.Errorf("RuneLen() == %d, want %d", r.RuneLen(), len([]rune(in)))
+	}
+	checkRope(t, r.root)
+
+	got, err := io.ReadAll(iotest.HalfReader(r.Reader()))
+	if err != nil || string(got) != in {
+		t.Errorf("Reader did not give back the text, %v", err)
+	}
+
+	var b strings.Builder
+	if n, err := r.WriteTo(&b); err != nil || n != int64(len(in)) || b.String() != in {
+		t.Errorf("WriteTo == %d, %v, want %d", n, err, len(in))
+	}
+
+	if _, err := ReadRope(iotest.ErrReader(io.ErrUnexpectedEOF)); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadRope of a failing reader == %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
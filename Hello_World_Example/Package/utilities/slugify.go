@@ -0,0 +1,180 @@
+package utilities
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Transliterate spells s in ASCII. Accents are dropped, Greek and Cyrillic are
+// romanized and letters such as ß or æ are written out using the tables in
+// transliterateTables.go. language is a BCP 47 tag such as "de" or "de-AT", it
+// picks the spellings of that language where they differ, so that the German
+// "ü" becomes "ue" instead of "u". Characters without an ASCII spelling, such
+// as Han, are left out
+/*
+utilities.Transliterate("Grüße aus Köln", "de") == "Gruesse aus Koeln"
+utilities.Transliterate("Москва", "") == "Moskva"
+*/
+func Transliterate(s, language string) string {
+	overrides := translitOverrides[baseLanguage(language)]
+	// Composed text so the overrides see ü and not u with U+0308
+	runes := []rune(Normalize(NFC, s))
+	var b strings.Builder
+	for i, r := range runes {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		lower := unicode.ToLower(r)
+		spelling, ok := overrides[lower]
+		if !ok {
+			spelling, ok = translitTable[lower]
+		}
+		if ok {
+			b.WriteString(translitCase(spelling, runes, i))
+			continue
+		}
+		// Whatever is left of the compatibility decomposition, é is e and U+0301
+		for _, d := range Normalize(NFKD, string(r)) {
+			switch {
+			case d < utf8.RuneSelf:
+				b.WriteRune(d)
+			case translitTable[unicode.ToLower(d)] != "":
+				b.WriteString(translitCase(translitTable[unicode.ToLower(d)], []rune{d}, 0))
+			case unicode.IsSpace(d):
+				b.WriteByte(' ')
+			case unicode.Is(unicode.Pd, d):
+				b.WriteByte('-')
+			}
+		}
+	}
+	return b.String()
+}
+
+// Returns the language part of a BCP 47 tag in lowercase, "de" for "de-AT"
+func baseLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(tag)
+}
+
+// Gives the lowercase spelling of runes[i] its case. A capital spelled with
+// several letters is all capitals inside of a word in capitals, "ЖУК" becomes
+// "ZHUK", and only starts with a capital otherwise, "Жук" becomes "Zhuk"
+func translitCase(spelling string, runes []rune, i int) string {
+	if !isUpper(runes[i]) || spelling == "" {
+		return spelling
+	}
+	next := i+1 < len(runes) && isUpper(runes[i+1])
+	previous := i > 0 && isUpper(runes[i-1]) && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+	if next || previous {
+		return strings.ToUpper(spelling)
+	}
+	r, size := utf8.DecodeRuneInString(spelling)
+	return string(unicode.ToUpper(r)) + spelling[size:]
+}
+
+// SlugOptions says how Slugify builds a slug
+type SlugOptions struct {
+	// Language is the BCP 47 tag of the text, it is passed on to Transliterate
+	Language string
+	// Separator goes between the words, "-" when empty
+	Separator string
+	// MaxLength is the most bytes a slug may have, 0 for no limit. Words at the
+	// end are left out until the slug fits, only a first word that is longer
+	// than MaxLength on its own is cut
+	MaxLength int
+}
+
+// Slugify turns s into lowercase ASCII words joined by hyphens, for use in URLs
+// and file names
+/*
+utilities.Slugify("Ελληνικά & Русский: 100% Unicode!") == "ellinika-russkiy-100-unicode"
+*/
+func Slugify(s string) string {
+	return SlugifyWithOptions(s, SlugOptions{})
+}
+
+// SlugifyWithOptions is Slugify with the given options
+func SlugifyWithOptions(s string, opts SlugOptions) string {
+	var words []string
+	var word strings.Builder
+	for _, r := range strings.ToLower(Transliterate(s, opts.Language)) {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			word.WriteRune(r)
+		case r == '\'':
+			// "don't" is one word
+		default:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return joinSlug(words, slugSeparator(opts), opts.MaxLength)
+}
+
+func slugSeparator(opts SlugOptions) string {
+	if opts.Separator == "" {
+		return "-"
+	}
+	return opts.Separator
+}
+
+// Joins as many of words as fit into maxLength bytes, all of them when it is 0
+func joinSlug(words []string, separator string, maxLength int) string {
+	if maxLength <= 0 {
+		return strings.Join(words, separator)
+	}
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 {
+			if b.Len()+len(separator)+len(w) > maxLength {
+				break
+			}
+			b.WriteString(separator)
+		} else if len(w) > maxLength {
+			return w[:maxLength]
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// UniqueSlug returns slug if taken reports it as free, otherwise the first free
+// one of slug-2, slug-3 and so on. With a MaxLength in opts words at the end
+// of slug make room for the number
+/*
+taken := map[string]bool{"hello-world": true, "hello-world-2": true}
+utilities.UniqueSlug("hello-world", utilities.SlugOptions{}, func(s string) bool { return taken[s] }) == "hello-world-3"
+*/
+func UniqueSlug(slug string, opts SlugOptions, taken func(string) bool) string {
+	if !taken(slug) {
+		return slug
+	}
+	separator := slugSeparator(opts)
+	for n := 2; ; n++ {
+		suffix := separator + strconv.Itoa(n)
+		base := slug
+		if room := opts.MaxLength - len(suffix); opts.MaxLength > 0 && len(base) > room {
+			base = ""
+			if room > 0 {
+				base = joinSlug(strings.Split(slug, separator), separator, room)
+			}
+		}
+		candidate := base + suffix
+		if base == "" {
+			candidate = strconv.Itoa(n)
+		}
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package utilities
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	cases := []struct {
+		in, language, want string
+	}{
+		{"Crème brûlée", "", "Creme brulee"},
+		{"Grüße aus Köln", "", "Grusse aus Koln"},
+		{"Grüße aus Köln", "de", "Gruesse aus Koeln"},
+		{"Grüße aus Köln", "de-AT", "Gruesse aus Koeln"},
+		// Decomposed text gets the same overrides
+		{"Gru\u0308ße", "de", "Gruesse"},
+		{"ÜBER", "de", "UEBER"},
+		{"Ærø og Åland", "", "Aero og Aland"},
+		{"Ærø og Åland", "da", "Aeroe og Aaland"},
+		{"Москва, Щука, ЖУК", "", "Moskva, Shchuka, ZHUK"},
+		{"Харків", "uk", "Kharkiv"},
+		{"Григорій", "uk", "Hryhorii"},
+		{"България", "bg", "Balgariya"},
+		{"Ελληνικά", "", "Ellinika"},
+		{"Θεσσαλονίκη", "", "Thessaloniki"},
+		{"Łódź, Øresund, Þingvellir", "", "Lodz, Oresund, Thingvellir"},
+		// Compatibility characters and typographic punctuation
+		{"ﬁnance ① “quoted” – dash", "", `finance 1 "quoted" - dash`},
+		{"東京 Tokyo", "", " Tokyo"},
+	}
+
+	for _, c := range cases {
+		got := Transliterate(c.in, c.language)
+		if got != c.want {
+			t.Errorf("Transliterate(%q, %q) == %q, want %q", c.in, c.language, got, c.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts SlugOptions
+		want string
+	}{
+		{"Hello, World!", SlugOptions{}, "hello-world"},
+		{"Ελληνικά & Русский: 100% Unicode!", SlugOptions{}, "ellinika-russkiy-100-unicode"},
+		{"Über uns", SlugOptions{Language: "de"}, "ueber-uns"},
+		{"Don't Panic", SlugOptions{Separator: "_"}, "dont_panic"},
+		{"  --Already--slugged--  ", SlugOptions{}, "already-slugged"},
+		// Words are never cut, unless the first one alone is too long
+		{"The quick brown fox", SlugOptions{MaxLength: 15}, "the-quick-brown"},
+		{"The quick brown fox", SlugOptions{MaxLength: 14}, "the-quick"},
+		{"Supercalifragilistic", SlugOptions{MaxLength: 5}, "super"},
+		{"東京", SlugOptions{}, ""},
+	}
+
+	for _, c := range cases {
+		got := SlugifyWithOptions(c.in, c.opts)
+		if got != c.want {
+			t.Errorf("SlugifyWithOptions(%q, %+v) == %q, want %q", c.in, c.opts, got, c.want)
+		}
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	taken := map[string]bool{
+		"hello-world": true, "hello-world-2": true,
+		"the-quick": true, "the-2": true,
+	}
+	isTaken := func(s string) bool { return taken[s] }
+
+	cases := []struct {
+		slug string
+		opts SlugOptions
+		want string
+	}{
+		{"new-post", SlugOptions{}, "new-post"},
+		{"hello-world", SlugOptions{}, "hello-world-3"},
+		// Words go to make room for the number
+		{"the-quick", SlugOptions{MaxLength: 9}, "the-3"},
+	}
+
+	for _, c := range cases {
+		got := UniqueSlug(c.slug, c.opts, isTaken)
+		if got != c.want {
+			t.Errorf("UniqueSlug(%q, %+v) == %q, want %q", c.slug, c.opts, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package utilities
+
+import (
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// OffsetUnit picks whether positions in a text are counted in bytes or runes
+type OffsetUnit int
+
+const (
+	// OffsetBytes counts positions in bytes, every byte is a symbol of its own
+	OffsetBytes OffsetUnit = iota
+	// OffsetRunes counts positions in runes, so no suffix starts inside of a
+	// rune. A byte that is not valid UTF-8 is a rune of its own, different from
+	// U+FFFD
+	OffsetRunes
+)
+
+// SuffixArray indexes a text for fast substring queries. It holds the start of
+// every suffix of the text in sorted order, built in linear time with the SA-IS
+// algorithm of Nong, Zhang and Chan, and next to it the length of the prefix
+// every suffix shares with the one before it, found with the algorithm of
+// Kasai et al. Once built it is safe to use from many goroutines at once
+/*
+x := utilities.NewSuffixArray("banana", utilities.OffsetBytes)
+x.Count("ana") == 2
+x.Locate("ana") // [1 3]
+x.LongestRepeated() // "ana", [1 3]
+*/
+type SuffixArray struct {
+	text string
+	unit OffsetUnit
+	// The text as symbols, bytes or the rank of every rune
+	symbols []int32
+	// For OffsetRunes the byte offset of every rune and then len(text), and
+	// the symbol of every distinct rune
+	starts   []int
+	alphabet map[int32]int32
+	sa, lcp  []int32
+}
+
+// Returns the key of a rune in OffsetRunes, invalid bytes come after all runes
+func suffixRuneKey(s string) (int32, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError && size == 1 {
+		return utf8.MaxRune + 1 + int32(s[0]), 1
+	}
+	return r, size
+}
+
+// NewSuffixArray builds the suffix array of text with offsets in unit
+func NewSuffixArray(text string, unit OffsetUnit) *SuffixArray {
+	x := &SuffixArray{text: text, unit: unit}
+	k := 256
+	if unit == OffsetRunes {
+		var keys []int32
+		for i := 0; i < len(text); {
+			key, size := suffixRuneKey(text[i:])
+			keys = append(keys, key)
+			x.starts = append(x.starts, i)
+			i += size
+		}
+		x.starts = append(x.starts, len(text))
+
+		// Ranks keep the order of the runes but fit into a small alphabet
+		sorted := slices.Clone(keys)
+		slices.Sort(sorted)
+		sorted = slices.Compact(sorted)
+		x.alphabet = make(map[int32]int32, len(sorted))
+		for i, key := range sorted {
+			x.alphabet[key] = int32(i)
+		}
+		x.symbols = make([]int32, len(keys))
+		for i, key := range keys {
+			x.symbols[i] = x.alphabet[key]
+		}
+		k = len(sorted)
+	} else {
+		x.symbols = make([]int32, len(text))
+		for i := 0; i < len(text); i++ {
+			x.symbols[i] = int32(text[i])
+		}
+	}
+	x.sa = sais(x.symbols, k)
+	x.lcp = kasai(x.symbols, x.sa)
+	return x
+}
+
+// Returns the suffix array of s, whose symbols are all below k. The end of s
+// is taken to be a sentinel smaller than every symbol
+func sais(s []int32, k int) []int32 {
+	n := len(s)
+	sa := make([]int32, n)
+	if n <= 1 {
+		return sa
+	}
+
+	// A suffix is S-type when it is smaller than the one after it, the last
+	// one is L-type since only the sentinel follows
+	sType := make([]bool, n)
+	for i := n - 2; i >= 0; i-- {
+		sType[i] = s[i] < s[i+1] || (s[i] == s[i+1] && sType[i+1])
+	}
+	// Leftmost S-type, an S-type suffix right after an L-type one
+	isLMS := func(i int) bool {
+		return i > 0 && sType[i] && !sType[i-1]
+	}
+
+	counts := make([]int32, k)
+	for _, c := range s {
+		counts[c]++
+	}
+	bucket := make([]int32, k)
+	heads := func() {
+		var sum int32
+		for c, count := range counts {
+			bucket[c] = sum
+			sum += count
+		}
+	}
+	tails := func() {
+		var sum int32
+		for c, count := range counts {
+			sum += count
+			bucket[c] = sum
+		}
+	}
+	// Sorts all suffixes from the LMS suffixes already at the ends of their buckets
+	induce := func() {
+		heads()
+		sa[bucket[s[n-1]]] = int32(n - 1)
+		bucket[s[n-1]]++
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; sa[i] > 0 && !sType[j] {
+				sa[bucket[s[j]]] = j
+				bucket[s[j]]++
+			}
+		}
+		tails()
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; sa[i] > 0 && sType[j] {
+				bucket[s[j]]--
+				sa[bucket[s[j]]] = j
+			}
+		}
+	}
+
+	// Sort the LMS substrings by inducing from the LMS suffixes in any order
+	for i := range sa {
+		sa[i] = -1
+	}
+	tails()
+	for i := n - 1; i > 0; i-- {
+		if isLMS(i) {
+			bucket[s[i]]--
+			sa[bucket[s[i]]] = int32(i)
+		}
+	}
+	induce()
+
+	// Name the LMS substrings by their order, equal ones get the same name
+	m := 0
+	for i := 0; i < n; i++ {
+		if isLMS(int(sa[i])) {
+			sa[m] = sa[i]
+			m++
+		}
+	}
+	for i := m; i < n; i++ {
+		sa[i] = -1
+	}
+	name, previous := int32(0), -1
+	for i := 0; i < m; i++ {
+		pos := int(sa[i])
+		if previous < 0 || !equalLMS(s, sType, isLMS, pos, previous) {
+			name++
+			previous = pos
+		}
+		// LMS positions are at least two apart, so pos/2 is unique
+		sa[m+pos/2] = name - 1
+	}
+	names := make([]int32, 0, m)
+	for i := m; i < n; i++ {
+		if sa[i] >= 0 {
+			names = append(names, sa[i])
+		}
+	}
+
+	// Sort the LMS suffixes, recursing when two LMS substrings were equal
+	var sorted []int32
+	if int(name) < m {
+		sorted = sais(names, int(name))
+	} else {
+		sorted = make([]int32, m)
+		for i, c := range names {
+			sorted[c] = int32(i)
+		}
+	}
+	lms := make([]int32, 0, m)
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			lms = append(lms, int32(i))
+		}
+	}
+
+	// Induce the whole array from the sorted LMS suffixes
+	for i := range sa {
+		sa[i] = -1
+	}
+	tails()
+	for i := m - 1; i >= 0; i-- {
+		pos := lms[sorted[i]]
+		bucket[s[pos]]--
+		sa[bucket[s[pos]]] = pos
+	}
+	induce()
+	return sa
+}
+
+// Reports whether the LMS substrings at a and b are the same, both run up to
+// and including the next LMS position
+func equalLMS(s []int32, sType []bool, isLMS func(int) bool, a, b int) bool {
+	n := len(s)
+	for d := 0; ; d++ {
+		if a+d == n || b+d == n {
+			// Only one of them reached the sentinel
+			return false
+		}
+		if s[a+d] != s[b+d] || sType[a+d] != sType[b+d] {
+			return false
+		}
+		if d > 0 && (isLMS(a+d) || isLMS(b+d)) {
+			return isLMS(a+d) && isLMS(b+d)
+		}
+	}
+}
+
+// Returns the LCP array of s, lcp[i] is the length of the prefix that the
+// suffixes sa[i-1] and sa[i] share and lcp[0] is 0
+func kasai(s []int32, sa []int32) []int32 {
+	n := len(s)
+	rank := make([]int32, n)
+	for i, p := range sa {
+		rank[p] = int32(i)
+	}
+	lcp := make([]int32, n)
+	h := 0
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		j := int(sa[rank[i]-1])
+		for i+h < n && j+h < n && s[i+h] == s[j+h] {
+			h++
+		}
+		lcp[rank[i]] = int32(h)
+		// The next suffix shares at least one symbol less
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
+
+// Suffixes returns the start of every suffix of the text in sorted order
+func (x *SuffixArray) Suffixes() []int {
+	out := make([]int, len(x.sa))
+	for i, p := range x.sa {
+		out[i] = int(p)
+	}
+	return out
+}
+
+// LCP returns the length of the prefix every suffix in sorted order shares
+// with the one before it, the first one is 0
+func (x *SuffixArray) LCP() []int {
+	out := make([]int, len(x.lcp))
+	for i, l := range x.lcp {
+		out[i] = int(l)
+	}
+	return out
+}
+
+// Turns pattern into symbols, false when it holds a rune the text does not have
+func (x *SuffixArray) patternSymbols(pattern string) ([]int32, bool) {
+	symbols := make([]int32, 0, len(pattern))
+	if x.unit != OffsetRunes {
+		for i := 0; i < len(pattern); i++ {
+			symbols = append(symbols, int32(pattern[i]))
+		}
+		return symbols, true
+	}
+	for i := 0; i < len(pattern); {
+		key, size := suffixRuneKey(pattern[i:])
+		symbol, ok := x.alphabet[key]
+		if !ok {
+			return nil, false
+		}
+		symbols = append(symbols, symbol)
+		i += size
+	}
+	return symbols, true
+}
+
+// Returns the range of sa whose suffixes start with pattern
+func (x *SuffixArray) lookup(pattern string) (int, int) {
+	p, ok := x.patternSymbols(pattern)
+	if !ok || len(p) == 0 {
+		return 0, 0
+	}
+	// Compares the suffix at sa[i] with p, 0 when p is a prefix of it
+	compare := func(i int) int {
+		suffix := x.symbols[x.sa[i]:]
+		for j, c := range p {
+			switch {
+			case j == len(suffix) || suffix[j] < c:
+				return -1
+			case suffix[j] > c:
+				return 1
+			}
+		}
+		return 0
+	}
+	lo := sort.Search(len(x.sa), func(i int) bool { return compare(i) >= 0 })
+	hi := sort.Search(len(x.sa), func(i int) bool { return compare(i) > 0 })
+	return lo, hi
+}
+
+// Count returns how many times pattern occurs in the text, overlapping
+// occurrences included. An empty pattern occurs nowhere
+func (x *SuffixArray) Count(pattern string) int {
+	lo, hi := x.lookup(pattern)
+	return hi - lo
+}
+
+// Locate returns the offsets of all occurrences of pattern in increasing order
+func (x *SuffixArray) Locate(pattern string) []int {
+	lo, hi := x.lookup(pattern)
+	if lo == hi {
+		return nil
+	}
+	out := make([]int, 0, hi-lo)
+	for _, p := range x.sa[lo:hi] {
+		out = append(out, int(p))
+	}
+	slices.Sort(out)
+	return out
+}
+
+// LongestRepeated returns the longest substring that occurs at least twice in
+// the text and the offsets of all its occurrences in increasing order. When
+// there are several, the one that sorts first wins. When no symbol repeats it
+// returns "" and nil
+func (x *SuffixArray) LongestRepeated() (string, []int) {
+	best := 0
+	for i, l := range x.lcp {
+		if l > x.lcp[best] {
+			best = i
+		}
+	}
+	if len(x.lcp) == 0 || x.lcp[best] == 0 {
+		return "", nil
+	}
+	length := x.lcp[best]
+	// All the suffixes around best that share the repeat
+	lo, hi := best-1, best+1
+	for lo > 0 && x.lcp[lo] >= length {
+		lo--
+	}
+	for hi < len(x.lcp) && x.lcp[hi] >= length {
+		hi++
+	}
+	offsets := make([]int, 0, hi-lo)
+	for _, p := range x.sa[lo:hi] {
+		offsets = append(offsets, int(p))
+	}
+	slices.Sort(offsets)
+
+	start, end := offsets[0], offsets[0]+int(length)
+	if x.unit == OffsetRunes {
+		start, end = x.starts[start], x.starts[end]
+	}
+	return x.text[start:end], offsets
+}
@@ -0,0 +1,123 @@
+package utilities
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Builds the suffix array and LCP array of s by sorting all suffixes
+func naiveSuffixArray(s []int32) ([]int, []int) {
+	sa := make([]int, len(s))
+	for i := range sa {
+		sa[i] = i
+	}
+	sort.Slice(sa, func(a, b int) bool { return slices.Compare(s[sa[a]:], s[sa[b]:]) < 0 })
+	lcp := make([]int, len(s))
+	for i := 1; i < len(sa); i++ {
+		a, b := s[sa[i-1]:], s[sa[i]:]
+		for lcp[i] < len(a) && lcp[i] < len(b) && a[lcp[i]] == b[lcp[i]] {
+			lcp[i]++
+		}
+	}
+	return sa, lcp
+}
+
+func TestSuffixArrayRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, alphabet := range []string{"ab", "abc", "acgt", "aé世\U0001F600"} {
+		letters := []rune(alphabet)
+		for round := 0; round < 200; round++ {
+			runes := make([]rune, rng.Intn(300))
+			for i := range runes {
+				runes[i] = letters[rng.Intn(len(letters))]
+			}
+			text := string(runes)
+
+			for _, unit := range []OffsetUnit{OffsetBytes, OffsetRunes} {
+				var symbols []int32
+				if unit == OffsetRunes {
+					symbols = runes
+				} else {
+					for i := 0; i < len(text); i++ {
+						symbols = append(symbols, int32(text[i]))
+					}
+				}
+				wantSA, wantLCP := naiveSuffixArray(symbols)
+				x := NewSuffixArray(text, unit)
+				if !slices.Equal(x.Suffixes(), wantSA) || !slices.Equal(x.LCP(), wantLCP) {
+					t.Fatalf("NewSuffixArray(%q, %d) == %v %v, want %v %v",
+						text, unit, x.Suffixes(), x.LCP(), wantSA, wantLCP)
+				}
+			}
+		}
+	}
+}
+
+func TestSuffixArrayQueries(t *testing.T) {
+	cases := []struct {
+		text, pattern string
+		unit          OffsetUnit
+		count         int
+		locate        []int
+	}{
+		{"banana", "ana", OffsetBytes, 2, []int{1, 3}},
+		{"banana", "a", OffsetBytes, 3, []int{1, 3, 5}},
+		{"banana", "nab", OffsetBytes, 0, nil},
+		{"banana", "bananas", OffsetBytes, 0, nil},
+		{"banana", "", OffsetBytes, 0, nil},
+		{"aaaa", "aa", OffsetBytes, 3, []int{0, 1, 2}},
+		// Byte and rune offsets part ways after the first wide character
+		{"世界, 世界!", "世界", OffsetBytes, 2, []int{0, 8}},
+		{"世界, 世界!", "世界", OffsetRunes, 2, []int{0, 4}},
+		{"世界, 世界!", "界!", OffsetRunes, 1, []int{5}},
+		{"世界, 世界!", "?", OffsetRunes, 0, nil},
+		// An invalid byte is not the same as U+FFFD
+		{"a\xffb�b", "\xffb", OffsetRunes, 1, []int{1}},
+	}
+
+	for _, c := range cases {
+		x := NewSuffixArray(c.text, c.unit)
+		if got := x.Count(c.pattern); got != c.count {
+			t.Errorf("NewSuffixArray(%q, %d).Count(%q) == %d, want %d", c.text, c.unit, c.pattern, got, c.count)
+		}
+		if got := x.Locate(c.pattern); !slices.Equal(got, c.locate) {
+			t.Errorf("NewSuffixArray(%q, %d).Locate(%q) == %v, want %v", c.text, c.unit, c.pattern, got, c.locate)
+		}
+	}
+}
+
+func TestLongestRepeated(t *testing.T) {
+	cases := []struct {
+		text    string
+		unit    OffsetUnit
+		want    string
+		offsets []int
+	}{
+		{"banana", OffsetBytes, "ana", []int{1, 3}},
+		{"abcabcabc", OffsetBytes, "abcabc", []int{0, 3}},
+		{"abc", OffsetBytes, "", nil},
+		{"", OffsetBytes, "", nil},
+		{"to be or not to be", OffsetBytes, "to be", []int{0, 13}},
+		{"世界世界", OffsetRunes, "世界", []int{0, 2}},
+		{"世界世界", OffsetBytes, "世界", []int{0, 6}},
+		{"xaaaay", OffsetBytes, "aaa", []int{1, 2}},
+	}
+
+	for _, c := range cases {
+		got, offsets := NewSuffixArray(c.text, c.unit).LongestRepeated()
+		if got != c.want || !slices.Equal(offsets, c.offsets) {
+			t.Errorf("NewSuffixArray(%q, %d).LongestRepeated() == %q, %v, want %q, %v",
+				c.text, c.unit, got, offsets, c.want, c.offsets)
+		}
+	}
+
+	// A large text is built in linear time
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 20000) + "!"
+	x := NewSuffixArray(text, OffsetRunes)
+	if got := x.Count("lazy dog"); got != 20000 {
+		t.Errorf("Count(%q) == %d, want 20000", "lazy dog", got)
+	}
+}
@@ -0,0 +1,371 @@
+package utilities
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a way of storing text as bytes. The zero value is UTF-8
+type Encoding int
+
+const (
+	// UTF8 is the encoding Go strings use
+	UTF8 Encoding = iota
+	// UTF16LE stores every character as one or, above U+FFFF, two 16 bit code
+	// units with the low byte first, as Windows does
+	UTF16LE
+	// UTF16BE is UTF-16 with the high byte first
+	UTF16BE
+	// Latin1 is ISO 8859-1, every byte is the character with the same number,
+	// so it only has the characters up to U+00FF
+	Latin1
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF8:
+		return "UTF-8"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case Latin1:
+		return "Latin-1"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// BOM returns the byte order mark of e, U+FEFF in the encoding, or nil for Latin1
+func (e Encoding) BOM() []byte {
+	switch e {
+	case UTF8:
+		return []byte{0xEF, 0xBB, 0xBF}
+	case UTF16LE:
+		return []byte{0xFF, 0xFE}
+	case UTF16BE:
+		return []byte{0xFE, 0xFF}
+	}
+	return nil
+}
+
+// SniffBOM looks for a byte order mark at the start of b and returns the
+// encoding it stands for and its length, or UTF8 and 0 when there is none
+/*
+e, n := utilities.SniffBOM(data)
+text := e.Decode(data[n:])
+*/
+func SniffBOM(b []byte) (Encoding, int) {
+	for _, e := range []Encoding{UTF8, UTF16LE, UTF16BE} {
+		if bom := e.BOM(); bytes.HasPrefix(b, bom) {
+			return e, len(bom)
+		}
+	}
+	return UTF8, 0
+}
+
+// EncodeError is returned when a character cannot be written in an encoding
+type EncodeError struct {
+	Encoding Encoding
+	Rune     rune
+	// Offset is the byte offset of the character in the UTF-8 input
+	Offset int64
+}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("utilities: %U at byte offset %d cannot be encoded in %v", e.Rune, e.Offset, e.Encoding)
+}
+
+// Decode returns b, which is in e, as UTF-8. Code units that do not form a
+// character, such as a surrogate without its pair, become U+FFFD. A byte order
+// mark is not removed, use SniffBOM for that
+/*
+utilities.UTF16LE.Decode([]byte{'H', 0, 'i', 0}) == "Hi"
+*/
+func (e Encoding) Decode(b []byte) string {
+	s, _ := TransformString(e.NewDecoder(), string(b))
+	return s
+}
+
+// Encode returns s in e. Invalid UTF-8 is written as U+FFFD, a character that
+// e does not have gives an *EncodeError
+func (e Encoding) Encode(s string) ([]byte, error) {
+	out, err := TransformString(e.NewEncoder(), s)
+	return []byte(out), err
+}
+
+// NewDecoder returns a Transformer that turns text in e into UTF-8
+func (e Encoding) NewDecoder() Transformer {
+	switch e {
+	case UTF16LE:
+		return utf16Decoder{binary.LittleEndian}
+	case UTF16BE:
+		return utf16Decoder{binary.BigEndian}
+	case Latin1:
+		return latin1Decoder{}
+	}
+	return &encoder{encoding: UTF8, put: putUTF8}
+}
+
+// NewEncoder returns a Transformer that turns UTF-8 into text in e
+func (e Encoding) NewEncoder() Transformer {
+	switch e {
+	case UTF16LE:
+		return &encoder{encoding: e, put: utf16Putter(binary.LittleEndian)}
+	case UTF16BE:
+		return &encoder{encoding: e, put: utf16Putter(binary.BigEndian)}
+	case Latin1:
+		return &encoder{encoding: e, put: func(dst []byte, r rune) (int, bool) {
+			if r > 0xFF {
+				return 0, false
+			}
+			if len(dst) > 0 {
+				dst[0] = byte(r)
+			}
+			return 1, true
+		}}
+	}
+	return &encoder{encoding: UTF8, put: putUTF8}
+}
+
+// NewDecodeReader returns a reader that reads r, which is in e, as UTF-8
+func NewDecodeReader(e Encoding, r io.Reader) *TransformReader {
+	return NewTransformReader(r, e.NewDecoder())
+}
+
+// NewSniffReader returns a reader that reads r as UTF-8. A byte order mark at
+// the start of r picks the encoding and is dropped, without one r is read in
+// fallback
+/*
+text, _ := io.ReadAll(utilities.NewSniffReader(utilities.Latin1, file))
+*/
+func NewSniffReader(fallback Encoding, r io.Reader) *TransformReader {
+	return NewTransformReader(r, &bomDecoder{fallback: fallback})
+}
+
+// NewEncodeWriter returns a writer that takes UTF-8 and writes it to w in e.
+// Close must be called to write a rune cut in two by the last Write
+func NewEncodeWriter(e Encoding, w io.Writer) *TransformWriter {
+	return NewTransformWriter(w, e.NewEncoder())
+}
+
+type latin1Decoder struct{}
+
+func (latin1Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := rune(src[nSrc])
+		if utf8.RuneLen(r) > len(dst)-nDst {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+func (latin1Decoder) Reset() {}
+
+type utf16Decoder struct {
+	order binary.ByteOrder
+}
+
+func (d utf16Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, n := utf8.RuneError, 1
+		rest := src[nSrc:]
+		switch {
+		case len(rest) < 2:
+			// An odd byte at the end
+			if !atEOF {
+				return nDst, nSrc, ErrShortSrc
+			}
+		case isHighSurrogate(d.order.Uint16(rest)):
+			if len(rest) < 4 && !atEOF {
+				return nDst, nSrc, ErrShortSrc
+			}
+			n = 2
+			if len(rest) >= 4 && isLowSurrogate(d.order.Uint16(rest[2:])) {
+				r, n = utf16.DecodeRune(rune(d.order.Uint16(rest)), rune(d.order.Uint16(rest[2:]))), 4
+			}
+		case isLowSurrogate(d.order.Uint16(rest)):
+			n = 2
+		default:
+			r, n = rune(d.order.Uint16(rest)), 2
+		}
+		if utf8.RuneLen(r) > len(dst)-nDst {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+func (utf16Decoder) Reset() {}
+
+func isHighSurrogate(u uint16) bool {
+	return 0xD800 <= u && u < 0xDC00
+}
+
+func isLowSurrogate(u uint16) bool {
+	return 0xDC00 <= u && u < 0xE000
+}
+
+func isSurrogate(u uint16) bool {
+	return 0xD800 <= u && u < 0xE000
+}
+
+// Writes r as UTF-8, so that invalid bytes become U+FFFD
+func putUTF8(dst []byte, r rune) (int, bool) {
+	n := utf8.RuneLen(r)
+	if len(dst) >= n {
+		utf8.EncodeRune(dst, r)
+	}
+	return n, true
+}
+
+// Writes r as UTF-16 code units, a character above U+FFFF as a surrogate pair
+func utf16Putter(order binary.ByteOrder) func(dst []byte, r rune) (int, bool) {
+	return func(dst []byte, r rune) (int, bool) {
+		if r < 0x10000 {
+			if len(dst) >= 2 {
+				order.PutUint16(dst, uint16(r))
+			}
+			return 2, true
+		}
+		if len(dst) >= 4 {
+			high, low := utf16.EncodeRune(r)
+			order.PutUint16(dst, uint16(high))
+			order.PutUint16(dst[2:], uint16(low))
+		}
+		return 4, true
+	}
+}
+
+// Turns UTF-8 into another encoding. put writes r to dst when it fits and
+// returns its length, or false when the encoding does not have it
+type encoder struct {
+	encoding Encoding
+	put      func(dst []byte, r rune) (int, bool)
+	offset   int64 // Of src in the whole input, for errors
+}
+
+func (t *encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 && !atEOF && !utf8.FullRune(src[nSrc:]) {
+			break
+		}
+		n, ok := t.put(dst[nDst:], r)
+		if !ok {
+			t.offset += int64(nSrc)
+			return nDst, nSrc, &EncodeError{Encoding: t.encoding, Rune: r, Offset: t.offset}
+		}
+		if n > len(dst)-nDst {
+			t.offset += int64(nSrc)
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += n
+		nSrc += size
+	}
+	t.offset += int64(nSrc)
+	if nSrc < len(src) {
+		return nDst, nSrc, ErrShortSrc
+	}
+	return nDst, nSrc, nil
+}
+
+func (t *encoder) Reset() {
+	t.offset = 0
+}
+
+// Decodes in the encoding of the byte order mark the input starts with
+type bomDecoder struct {
+	fallback Encoding
+	decoder  Transformer
+}
+
+func (t *bomDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if t.decoder == nil {
+		e, n := SniffBOM(src)
+		if n == 0 {
+			// Wait for the bytes that could still finish a mark
+			for _, bom := range [][]byte{UTF8.BOM(), UTF16LE.BOM(), UTF16BE.BOM()} {
+				if !atEOF && len(src) < len(bom) && bytes.HasPrefix(bom, src) {
+					return 0, 0, ErrShortSrc
+				}
+			}
+			e = t.fallback
+		}
+		t.decoder = e.NewDecoder()
+		nSrc = n
+	}
+	nDst, n, err := t.decoder.Transform(dst, src[nSrc:], atEOF)
+	return nDst, nSrc + n, err
+}
+
+func (t *bomDecoder) Reset() {
+	t.decoder = nil
+}
+
+// ReverseEncoded reverses the characters of b, which is in e, without decoding
+// it. A surrogate pair of UTF-16 stays together and in order, and so does a
+// run of code units or bytes that are not part of a character, so reversing
+// twice gives back b and never makes a character that was not there. A byte
+// order mark stays at the start and an odd byte at the end of UTF-16 stays at
+// the end
+/*
+le, _ := utilities.UTF16LE.Encode("a😀b")
+utilities.UTF16LE.Decode(utilities.ReverseEncoded(utilities.UTF16LE, le)) == "b😀a"
+*/
+func ReverseEncoded(e Encoding, b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	if bom := e.BOM(); bom != nil && bytes.HasPrefix(b, bom) {
+		out = append(out, bom...)
+		b = b[len(bom):]
+	}
+
+	switch e {
+	case Latin1:
+		for i := len(b) - 1; i >= 0; i-- {
+			out = append(out, b[i])
+		}
+	case UTF16LE, UTF16BE:
+		order := binary.ByteOrder(binary.LittleEndian)
+		if e == UTF16BE {
+			order = binary.BigEndian
+		}
+		units := len(b) / 2
+		unit := func(j int) uint16 { return order.Uint16(b[2*j:]) }
+		paired := func(j int) bool {
+			return j+1 < units && isHighSurrogate(unit(j)) && isLowSurrogate(unit(j+1))
+		}
+		// Cut b into characters from the front. A run of surrogates that are
+		// not in a pair is one piece, as KeepInvalid does for UTF-8, so that a
+		// low one followed by a high one does not become a pair
+		var pieces [][]byte
+		for j := 0; j < units; {
+			n := 1
+			switch u := unit(j); {
+			case paired(j):
+				n = 2
+			case isSurrogate(u):
+				for j+n < units && isSurrogate(unit(j+n)) && !paired(j+n) {
+					n++
+				}
+			}
+			pieces = append(pieces, b[2*j:2*(j+n)])
+			j += n
+		}
+		for k := len(pieces) - 1; k >= 0; k-- {
+			out = append(out, pieces[k]...)
+		}
+		out = append(out, b[2*units:]...)
+	default:
+		reversed, _ := ReverseWithOptions(string(b), ReverseOptions{Invalid: KeepInvalid})
+		out = append(out, reversed...)
+	}
+	return out
+}
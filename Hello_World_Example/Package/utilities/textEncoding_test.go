@@ -0,0 +1,161 @@
+package utilities
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	cases := []struct {
+		e    Encoding
+		text string
+		want []byte
+	}{
+		{UTF16LE, "Hi", []byte{'H', 0, 'i', 0}},
+		{UTF16BE, "Hi", []byte{0, 'H', 0, 'i'}},
+		// U+1F600 is the surrogate pair D83D DE00
+		{UTF16LE, "a\U0001F600", []byte{'a', 0, 0x3D, 0xD8, 0x00, 0xDE}},
+		{UTF16BE, "a\U0001F600", []byte{0, 'a', 0xD8, 0x3D, 0xDE, 0x00}},
+		{UTF16LE, "世", []byte{0x16, 0x4E}},
+		{Latin1, "café", []byte{'c', 'a', 'f', 0xE9}},
+		{UTF8, "café", []byte("café")},
+	}
+
+	for _, c := range cases {
+		got, err := c.e.Encode(c.text)
+		if err != nil || !bytes.Equal(got, c.want) {
+			t.Errorf("%v.Encode(%q) == % x, %v, want % x", c.e, c.text, got, err, c.want)
+		}
+		if back := c.e.Decode(c.want); back != c.text {
+			t.Errorf("%v.Decode(% x) == %q, want %q", c.e, c.want, back, c.text)
+		}
+	}
+}
+
+func TestDecodeBroken(t *testing.T) {
+	cases := []struct {
+		e    Encoding
+		in   []byte
+		want string
+	}{
+		// A high surrogate without its low one and a low one on its own
+		{UTF16LE, []byte{0x3D, 0xD8, 'a', 0}, "�a"},
+		{UTF16LE, []byte{0x00, 0xDE, 'a', 0}, "�a"},
+		{UTF16BE, []byte{0xD8, 0x3D}, "�"},
+		// An odd byte at the end
+		{UTF16LE, []byte{'a', 0, 'b'}, "a�"},
+		{UTF8, []byte("a\xffb"), "a�b"},
+	}
+
+	for _, c := range cases {
+		if got := c.e.Decode(c.in); got != c.want {
+			t.Errorf("%v.Decode(% x) == %q, want %q", c.e, c.in, got, c.want)
+		}
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	_, err := Latin1.Encode("café 世界")
+	var encodeErr *EncodeError
+	if !errors.As(err, &encodeErr) || encodeErr.Rune != '世' || encodeErr.Offset != 6 {
+		t.Errorf("Latin1.Encode == %v, want an *EncodeError for 世 at 6", err)
+	}
+}
+
+func TestSniffReader(t *testing.T) {
+	le, _ := UTF16LE.Encode("Hello, 世界 \U0001F600")
+	be, _ := UTF16BE.Encode("Hello, 世界 \U0001F600")
+	latin, _ := Latin1.Encode("Hello, café")
+	cases := []struct {
+		in   []byte
+		want string
+	}{
+		{append(UTF16LE.BOM(), le...), "Hello, 世界 \U0001F600"},
+		{append(UTF16BE.BOM(), be...), "Hello, 世界 \U0001F600"},
+		{append(UTF8.BOM(), "Hello"...), "Hello"},
+		// No mark, so the fallback is used
+		{latin, "Hello, café"},
+		{[]byte{0xFF}, "ÿ"},
+		{nil, ""},
+	}
+
+	for _, c := range cases {
+		// A byte at a time, so the mark and the surrogate pairs are cut apart
+		got, err := io.ReadAll(NewSniffReader(Latin1, iotest.OneByteReader(bytes.NewReader(c.in))))
+		if err != nil || string(got) != c.want {
+			t.Errorf("Reading % x == %q, %v, want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestEncodeWriter(t *testing.T) {
+	text := strings.Repeat("Hello, 世界 \U0001F600 ", 500)
+	for _, e := range []Encoding{UTF16LE, UTF16BE} {
+		want, _ := e.Encode(text)
+		var b bytes.Buffer
+		w := NewEncodeWriter(e, &b)
+		for i := 0; i < len(text); i += 5 {
+			w.Write([]byte(text[i:min(i+5, len(text))]))
+		}
+		if err := w.Close(); err != nil || !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("NewEncodeWriter(%v) wrote something else, %v", e, err)
+		}
+
+		got, err := io.ReadAll(NewDecodeReader(e, iotest.HalfReader(bytes.NewReader(want))))
+		if err != nil || string(got) != text {
+			t.Errorf("NewDecodeReader(%v) read something else, %v", e, err)
+		}
+	}
+}
+
+func TestReverseEncoded(t *testing.T) {
+	cases := []struct {
+		e          Encoding
+		text, want string
+	}{
+		{UTF16LE, "a\U0001F600b", "b\U0001F600a"},
+		{UTF16BE, "Hello, 世界", "界世 ,olleH"},
+		{Latin1, "café", "éfac"},
+		{UTF8, "a\U0001F600b", "b\U0001F600a"},
+	}
+
+	for _, c := range cases {
+		in, _ := c.e.Encode(c.text)
+		got := ReverseEncoded(c.e, in)
+		if c.e.Decode(got) != c.want {
+			t.Errorf("ReverseEncoded(%v, %q) == %q, want %q", c.e, c.text, c.e.Decode(got), c.want)
+		}
+		// The byte order mark stays in front
+		withBOM := append(c.e.BOM(), in...)
+		if got := ReverseEncoded(c.e, withBOM); !bytes.Equal(got[:len(c.e.BOM())], c.e.BOM()) {
+			t.Errorf("ReverseEncoded(%v) moved the byte order mark", c.e)
+		}
+	}
+
+	// Broken code units are moved as they are, so reversing twice changes nothing
+	broken := []byte{'a', 0, 0x3D, 0xD8, 0x3D, 0xD8, 0x00, 0xDE, 0x00, 0xDE, 'b'}
+	if got := ReverseEncoded(UTF16LE, ReverseEncoded(UTF16LE, broken)); !bytes.Equal(got, broken) {
+		t.Errorf("Reversing % x twice == % x", broken, got)
+	}
+
+	// Runs of broken units or bytes keep their order instead of turning into
+	// a surrogate pair or a character
+	runs := []struct {
+		e        Encoding
+		in, want []byte
+	}{
+		{UTF16LE, []byte{0x00, 0xDC, 0x00, 0xD8}, []byte{0x00, 0xDC, 0x00, 0xD8}},
+		{UTF16BE, []byte{0, 'a', 0xDC, 0x00, 0xD8, 0x00, 0, 'b'}, []byte{0, 'b', 0xDC, 0x00, 0xD8, 0x00, 0, 'a'}},
+		{UTF8, []byte("\xa9\xc3"), []byte("\xa9\xc3")},
+		{UTF8, []byte("a\xa9\xc3b"), []byte("b\xa9\xc3a")},
+	}
+	for _, c := range runs {
+		if got := ReverseEncoded(c.e, c.in); !bytes.Equal(got, c.want) {
+			t.Errorf("ReverseEncoded(%v, % x) == % x, want % x", c.e, c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package utilities
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Transformer changes a stream of text piece by piece, it works the same way as
+// golang.org/x/text/transform.Transformer. Transform writes the transformed
+// form of a prefix of src to dst and returns how many bytes it wrote and read.
+// atEOF says that src is the rest of the input. When dst has no room left it
+// returns ErrShortDst, when it needs more of src before it can go on it returns
+// ErrShortSrc. A Transformer may hold state between calls, Reset clears it so
+// that a new input can start
+type Transformer interface {
+	Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error)
+	Reset()
+}
+
+var (
+	// ErrShortDst means that dst was too short to go on
+	ErrShortDst = errors.New("utilities: short destination buffer")
+	// ErrShortSrc means that src has to be longer to go on
+	ErrShortSrc = errors.New("utilities: short source buffer")
+)
+
+// TransformUnit says how much of the input a function given to
+// NewStringTransformer needs to see at once
+type TransformUnit int
+
+const (
+	// UnitRunes hands the function any number of whole runes, it has to give the
+	// same result for a text as for its pieces put together
+	UnitRunes TransformUnit = iota
+	// UnitWords never cuts the text inside of a word, pieces end in white space
+	UnitWords
+	// UnitLines hands the function whole lines, pieces end in a newline
+	UnitLines
+	// UnitAll waits for the end of the input and hands the function all of it
+	UnitAll
+)
+
+// NewStringTransformer returns a Transformer that calls f with the pieces of
+// the input given by unit
+/*
+shout := utilities.NewStringTransformer(strings.ToUpper, utilities.UnitRunes)
+*/
+func NewStringTransformer(f func(string) string, unit TransformUnit) Transformer {
+	return &pieceTransformer{
+		safe: func(src []byte, atEOF bool) int {
+			if atEOF {
+				return len(src)
+			}
+			switch unit {
+			case UnitRunes:
+				return fullRunesLen(src)
+			case UnitWords:
+				return strings.LastIndexFunc(string(src), unicode.IsSpace) + 1
+			case UnitLines:
+				return strings.LastIndexByte(string(src), '\n') + 1
+			}
+			return 0
+		},
+		apply: func(dst, piece []byte) []byte {
+			return append(dst, f(string(piece))...)
+		},
+	}
+}
+
+// Returns the length of the longest prefix of p that does not end in a
+// rune cut in two
+func fullRunesLen(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
+
+// NewNormalizeTransformer returns a Transformer that puts text into the
+// normalization form, holding back only what the next bytes could change
+func NewNormalizeTransformer(form Form) Transformer {
+	return &pieceTransformer{
+		safe: func(src []byte, atEOF bool) int {
+			return normSafeLen(form, src, atEOF)
+		},
+		apply: func(dst, piece []byte) []byte {
+			return appendNormalized(dst, form, string(piece))
+		},
+	}
+}
+
+// Transforms the pieces of the input that safe says can be handled on their own,
+// output that does not fit into dst waits in pending for the next call
+type pieceTransformer struct {
+	safe    func(src []byte, atEOF bool) int
+	apply   func(dst, piece []byte) []byte
+	buf     []byte
+	pending []byte
+}
+
+func (t *pieceTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for {
+		n := copy(dst[nDst:], t.pending)
+		nDst += n
+		t.pending = t.pending[n:]
+		if len(t.pending) > 0 {
+			return nDst, nSrc, ErrShortDst
+		}
+		if nSrc == len(src) {
+			return nDst, nSrc, nil
+		}
+		safe := t.safe(src[nSrc:], atEOF)
+		if safe == 0 {
+			return nDst, nSrc, ErrShortSrc
+		}
+		t.buf = t.apply(t.buf[:0], src[nSrc:nSrc+safe])
+		t.pending = t.buf
+		nSrc += safe
+	}
+}
+
+func (t *pieceTransformer) Reset() {
+	t.pending = nil
+}
+
+// Chain returns a Transformer that runs the output of every Transformer through
+// the next one
+/*
+t := utilities.Chain(utilities.NewNormalizeTransformer(utilities.NFC), lower)
+*/
+func Chain(ts ...Transformer) Transformer {
+	if len(ts) == 0 {
+		return NewStringTransformer(func(s string) string { return s }, UnitRunes)
+	}
+	c := &chain{ts: ts, bufs: make([]chainBuf, len(ts)-1), done: make([]bool, len(ts))}
+	for i := range c.bufs {
+		c.bufs[i].data = make([]byte, 4096)
+	}
+	return c
+}
+
+type chain struct {
+	ts []Transformer
+	// bufs[i] holds what ts[i] wrote and ts[i+1] did not read yet
+	bufs []chainBuf
+	// done[i] is set once ts[i] has read the end of its input
+	done []bool
+}
+
+type chainBuf struct {
+	data       []byte
+	start, end int
+}
+
+func (c *chain) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	last := len(c.ts) - 1
+	var lastErr error
+	for progress := true; progress; {
+		progress = false
+		for i, t := range c.ts {
+			if c.done[i] {
+				continue
+			}
+			in, inEOF := src[nSrc:], atEOF
+			if i > 0 {
+				b := &c.bufs[i-1]
+				in, inEOF = b.data[b.start:b.end], c.done[i-1]
+			}
+			out := dst[nDst:]
+			if i < last {
+				b := &c.bufs[i]
+				b.end = copy(b.data, b.data[b.start:b.end])
+				b.start = 0
+				out = b.data[b.end:]
+			}
+
+			nd, ns, err := t.Transform(out, in, inEOF)
+			if nd > 0 || ns > 0 {
+				progress = true
+			}
+			if i == 0 {
+				nSrc += ns
+			} else {
+				c.bufs[i-1].start += ns
+			}
+			if i < last {
+				c.bufs[i].end += nd
+			} else {
+				nDst += nd
+				lastErr = err
+			}
+			switch {
+			case err == nil && inEOF && ns == len(in):
+				c.done[i] = true
+				progress = true
+			case err == ErrShortSrc && i > 0 && ns == 0 && len(in) == len(c.bufs[i-1].data):
+				// The buffer in front of ts[i] is full and still too short,
+				// as with the reverse steps that need all of the text
+				b := &c.bufs[i-1]
+				b.data = append(b.data, make([]byte, len(b.data))...)
+				progress = true
+			case err != nil && err != ErrShortDst && err != ErrShortSrc:
+				return nDst, nSrc, err
+			}
+		}
+	}
+
+	switch {
+	case c.done[last]:
+		return nDst, nSrc, nil
+	case lastErr == ErrShortDst || atEOF:
+		return nDst, nSrc, ErrShortDst
+	case nSrc < len(src):
+		return nDst, nSrc, ErrShortSrc
+	}
+	return nDst, nSrc, nil
+}
+
+func (c *chain) Reset() {
+	for i, t := range c.ts {
+		t.Reset()
+		c.done[i] = false
+	}
+	for i := range c.bufs {
+		c.bufs[i].start, c.bufs[i].end = 0, 0
+	}
+}
+
+// TransformString runs all of s through t, which is Reset first
+/*
+out, err := utilities.TransformString(t, "Hello, World")
+*/
+func TransformString(t Transformer, s string) (string, error) {
+	t.Reset()
+	src := []byte(s)
+	dst := make([]byte, max(len(s), 64))
+	var out []byte
+	for {
+		nDst, nSrc, err := t.Transform(dst, src, true)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+		switch {
+		case err == nil:
+			return string(out), nil
+		case err == ErrShortDst:
+			if nDst == 0 && nSrc == 0 {
+				dst = make([]byte, 2*len(dst))
+			}
+		default:
+			return string(out), err
+		}
+	}
+}
+
+// TransformReader reads the output of a Transformer run over another reader.
+// Only as much of the input as the Transformer needs is held in memory
+type TransformReader struct {
+	r   io.Reader
+	t   Transformer
+	err error // From r, returned once everything before it is handed out
+
+	src              []byte
+	srcStart, srcEnd int
+	dst              []byte
+	dstStart, dstEnd int
+	complete         bool
+}
+
+// NewTransformReader returns a reader that reads r through t, which is Reset first
+/*
+lower, _ := utilities.ParsePipeline("nfc|lower")
+io.Copy(os.Stdout, utilities.NewTransformReader(os.Stdin, lower))
+*/
+func NewTransformReader(r io.Reader, t Transformer) *TransformReader {
+	t.Reset()
+	return &TransformReader{r: r, t: t, src: make([]byte, 4096), dst: make([]byte, 4096)}
+}
+
+// Read reads transformed text into p
+func (tr *TransformReader) Read(p []byte) (int, error) {
+	for {
+		if tr.dstStart < tr.dstEnd {
+			n := copy(p, tr.dst[tr.dstStart:tr.dstEnd])
+			tr.dstStart += n
+			return n, nil
+		}
+		if tr.complete {
+			return 0, tr.err
+		}
+
+		if tr.srcStart < tr.srcEnd || tr.err != nil {
+			nDst, nSrc, err := tr.t.Transform(tr.dst, tr.src[tr.srcStart:tr.srcEnd], tr.err != nil)
+			tr.srcStart += nSrc
+			tr.dstStart, tr.dstEnd = 0, nDst
+			switch {
+			case err == nil:
+				tr.complete = tr.err != nil
+				continue
+			case err == ErrShortDst:
+				if nDst == 0 && nSrc == 0 {
+					tr.dst = make([]byte, 2*len(tr.dst))
+				}
+				continue
+			case err != ErrShortSrc:
+				tr.err, tr.complete = err, true
+				continue
+			}
+			if nDst > 0 {
+				continue
+			}
+		}
+
+		// More input is needed, the buffer grows when the Transformer
+		// needs more of it than it holds
+		if tr.srcStart > 0 {
+			tr.srcEnd = copy(tr.src, tr.src[tr.srcStart:tr.srcEnd])
+			tr.srcStart = 0
+		} else if tr.srcEnd == len(tr.src) {
+			tr.src = append(tr.src, make([]byte, len(tr.src))...)
+		}
+		n, err := tr.r.Read(tr.src[tr.srcEnd:])
+		tr.srcEnd += n
+		tr.err = err
+	}
+}
+
+// TransformWriter runs the text written to it through a Transformer before
+// passing it on to another writer. Close must be called to write the text
+// that is held back
+type TransformWriter struct {
+	w   io.Writer
+	t   Transformer
+	src []byte // Written but not transformed yet
+	dst []byte
+}
+
+// NewTransformWriter returns a writer that writes to w through t, which is Reset first
+func NewTransformWriter(w io.Writer, t Transformer) *TransformWriter {
+	t.Reset()
+	return &TransformWriter{w: w, t: t, dst: make([]byte, 4096)}
+}
+
+// Write transforms p and writes all of it that can be transformed already
+func (tw *TransformWriter) Write(p []byte) (int, error) {
+	tw.src = append(tw.src, p...)
+	if err := tw.flush(false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the text that was held back, it does not close the underlying writer
+func (tw *TransformWriter) Close() error {
+	return tw.flush(true)
+}
+
+func (tw *TransformWriter) flush(atEOF bool) error {
+	for {
+		nDst, nSrc, err := tw.t.Transform(tw.dst, tw.src, atEOF)
+		tw.src = tw.src[:copy(tw.src, tw.src[nSrc:])]
+		if nDst > 0 {
+			if _, werr := tw.w.Write(tw.dst[:nDst]); werr != nil {
+				return werr
+			}
+		}
+		switch {
+		case err == ErrShortDst:
+			if nDst == 0 && nSrc == 0 {
+				tw.dst = make([]byte, 2*len(tw.dst))
+			}
+		case err == ErrShortSrc && !atEOF, err == nil:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// The transformers ParsePipeline knows, the argument is what follows a colon
+var pipelineSteps = map[string]func(arg string) (Transformer, error){
+	"nfc":               formStep(NFC),
+	"nfd":               formStep(NFD),
+	"nfkc":              formStep(NFKC),
+	"nfkd":              formStep(NFKD),
+	"lower":             stringStep(strings.ToLower, UnitRunes),
+	"upper":             stringStep(strings.ToUpper, UnitRunes),
+	"reverse":           stringStep(Reverse, UnitAll),
+	"reverse-graphemes": stringStep(ReverseGraphemes, UnitAll),
+	"reverse-words":     stringStep(ReverseWords, UnitAll),
+	"reverse-lines":     stringStep(ReverseLines, UnitAll),
+	"translit": func(language string) (Transformer, error) {
+		return NewStringTransformer(func(s string) string { return Transliterate(s, language) }, UnitWords), nil
+	},
+	"wrap": func(arg string) (Transformer, error) {
+		width, err := strconv.Atoi(arg)
+		if err != nil || width < 1 {
+			return nil, fmt.Errorf("utilities: wrap needs a width, not %q", arg)
+		}
+		return NewStringTransformer(func(s string) string { return Wrap(s, WrapOptions{Width: width}) }, UnitLines), nil
+	},
+}
+
+func formStep(form Form) func(string) (Transformer, error) {
+	return func(arg string) (Transformer, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("utilities: %s takes no argument", strings.ToLower(form.String()))
+		}
+		return NewNormalizeTransformer(form), nil
+	}
+}
+
+func stringStep(f func(string) string, unit TransformUnit) func(string) (Transformer, error) {
+	return func(arg string) (Transformer, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("utilities: unexpected argument %q", arg)
+		}
+		return NewStringTransformer(f, unit), nil
+	}
+}
+
+// ParsePipeline turns a spec such as "nfc|lower|reverse" into the Chain of its
+// steps, so that command lines can take a pipeline as a flag. The steps are
+// nfc, nfd, nfkc and nfkd, lower and upper, reverse, reverse-graphemes,
+// reverse-words and reverse-lines, translit or translit:de with the language
+// for Transliterate and wrap:72 with the width for Wrap. The reverse steps
+// need the whole input before they can write anything
+/*
+t, err := utilities.ParsePipeline("nfc|lower|reverse")
+out, err := utilities.TransformString(t, "Hello")
+*/
+func ParsePipeline(spec string) (Transformer, error) {
+	var ts []Transformer
+	for _, step := range strings.Split(spec, "|") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(step), ":")
+		build, ok := pipelineSteps[name]
+		if !ok {
+			return nil, fmt.Errorf("utilities: unknown pipeline step %q", name)
+		}
+		t, err := build(arg)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	if len(ts) == 1 {
+		return ts[0], nil
+	}
+	return Chain(ts...), nil
+}
@@ -0,0 +1,104 @@
+package utilities
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParsePipeline(t *testing.T) {
+	cases := []struct {
+		spec, in, want string
+	}{
+		{"nfc", "École", "École"},
+		{"nfc|lower|reverse", "ÉCOLE", "elocé"},
+		// Without nfc the accent ends up on the wrong letter
+		{"lower|reverse", "ÉCOLE", "eloće"},
+		{"nfd | upper", "été", "ÉTÉ"},
+		{"reverse-graphemes", "café", "éfac"},
+		{"reverse-words", "Hello, world!\n", "world! Hello,\n"},
+		{"reverse-lines", "a\nb\n", "b\na\n"},
+		{"translit:de|upper", "Grüße aus Köln", "GRUESSE AUS KOELN"},
+		{"translit", "Москва", "Moskva"},
+		{"wrap:5|reverse-lines", "aaa bbb ccc\n", "ccc\nbbb\naaa\n"},
+	}
+
+	for _, c := range cases {
+		tr, err := ParsePipeline(c.spec)
+		if err != nil {
+			t.Errorf("ParsePipeline(%q) failed: %v", c.spec, err)
+			continue
+		}
+		got, err := TransformString(tr, c.in)
+		if err != nil || got != c.want {
+			t.Errorf("ParsePipeline(%q) on %+q == %+q, %v, want %+q", c.spec, c.in, got, err, c.want)
+		}
+	}
+
+	for _, spec := range []string{"", "nfc|", "sideways", "wrap", "wrap:x", "nfc:1", "lower:x"} {
+		if _, err := ParsePipeline(spec); err == nil {
+			t.Errorf("ParsePipeline(%q) did not fail", spec)
+		}
+	}
+}
+
+// Runs t over src with room for only n bytes of output at a time
+func transformInSteps(t Transformer, src []byte, n int) (string, error) {
+	t.Reset()
+	var out []byte
+	dst := make([]byte, n)
+	for {
+		nDst, nSrc, err := t.Transform(dst, src, true)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+		if err != ErrShortDst {
+			return string(out), err
+		}
+	}
+}
+
+func TestTransformStreaming(t *testing.T) {
+	in := strings.Repeat("ÉCOLE, École. Straße\n", 2000)
+	for _, spec := range []string{"nfc|lower|reverse", "nfd|lower", "translit:de|wrap:12"} {
+		tr, err := ParsePipeline(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := TransformString(tr, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := transformInSteps(tr, []byte(in), 7); err != nil || got != want {
+			t.Errorf("%q with a short dst gave a different result, %v", spec, err)
+		}
+
+		// A byte at a time, so runes and marks are cut apart
+		got, err := io.ReadAll(NewTransformReader(iotest.OneByteReader(strings.NewReader(in)), tr))
+		if err != nil || string(got) != want {
+			t.Errorf("%q through a TransformReader gave a different result, %v", spec, err)
+		}
+
+		var b bytes.Buffer
+		w := NewTransformWriter(&b, tr)
+		for i := 0; i < len(in); i += 3 {
+			if _, err := w.Write([]byte(in[i:min(i+3, len(in))])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil || b.String() != want {
+			t.Errorf("%q through a TransformWriter gave a different result, %v", spec, err)
+		}
+	}
+}
+
+func TestTransformReaderError(t *testing.T) {
+	tr, _ := ParsePipeline("upper")
+	r := io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	got, err := io.ReadAll(NewTransformReader(r, tr))
+	if string(got) != "ABC" || err != io.ErrUnexpectedEOF {
+		t.Errorf("Reading a failing reader == %q, %v, want %q, %v", got, err, "ABC", io.ErrUnexpectedEOF)
+	}
+}
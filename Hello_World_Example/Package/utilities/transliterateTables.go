@@ -0,0 +1,57 @@
+package utilities
+
+// These tables are kept by hand. Keys are lowercase, Transliterate works out
+// the case of the replacement from the letter it replaces. Letters that are
+// only a base letter with accents, such as é or ǚ, do not need an entry since
+// their compatibility decomposition already leads to ASCII
+
+// ASCII spellings of letters and symbols that do not decompose to ASCII. Greek
+// follows ELOT 743 and Cyrillic the Russian BGN/PCGN system, both without their
+// diacritics, the other Cyrillic letters use the spelling of their own language
+var translitTable = map[rune]string{
+	// Latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ł': "l", 'ı': "i", 'ħ': "h", 'ŧ': "t", 'ŋ': "ng", 'ĸ': "q", 'ƒ': "f",
+	'ɐ': "a", 'ə': "e", 'ɛ': "e", 'ɔ': "o",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+
+	// Russian
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+
+	// Ukrainian and Belarusian
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+
+	// Serbian and Macedonian
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz", 'ѓ': "gj",
+	'ќ': "kj", 'ѕ': "dz",
+
+	// Punctuation and symbols
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '“': `"`, '”': `"`, '„': `"`,
+	'«': `"`, '»': `"`, '‹': "'", '›': "'", '–': "-", '—': "-", '―': "-",
+	'−': "-", '×': "x", '÷': "/", '©': "(c)", '®': "(r)", '€': "EUR",
+}
+
+// Spellings that differ from translitTable in a language, keyed by the
+// language part of its BCP 47 tag
+var translitOverrides = map[string]map[rune]string{
+	// German writes the umlauts out when they cannot be typed
+	"de": {'ä': "ae", 'ö': "oe", 'ü': "ue"},
+	// Danish and Norwegian
+	"da": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"nb": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"nn": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"no": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	// The Ukrainian national system of 2010
+	"uk": {'г': "h", 'и': "y", 'й': "i"},
+	// The Bulgarian system of 2009
+	"bg": {'х': "h", 'щ': "sht", 'ъ': "a"},
+}
@@ -0,0 +1,131 @@
+package utilities
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// The tables in widthTables.go are generated from the Unicode Character Database
+//go:generate go run genWidthTables.go
+
+type widthRange struct {
+	lo, hi rune
+}
+
+// Binary searches the generated table to find out if r takes two columns
+func isWide(r rune) bool {
+	lo, hi := 0, len(wideTable)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch e := &wideTable[m]; {
+		case r < e.lo:
+			hi = m
+		case r > e.hi:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the number of columns a single grapheme cluster takes up. The first
+// rune decides, the marks after it are drawn on top of it
+func clusterWidth(g string) int {
+	r, size := utf8.DecodeRuneInString(g)
+	switch breakProperty(r) {
+	case gbCR, gbLF, gbControl, gbExtend, gbZWJ:
+		// Controls and marks with nothing to sit on are not drawn
+		return 0
+	case gbRegionalIndicator:
+		// A pair of regional indicators is drawn as a flag
+		if len(g) > size {
+			return 2
+		}
+		return 1
+	case gbExtendedPictographic:
+		// U+FE0F VARIATION SELECTOR-16 asks for the wide emoji form
+		if strings.ContainsRune(g[size:], '\uFE0F') {
+			return 2
+		}
+	}
+	if isWide(r) {
+		return 2
+	}
+	return 1
+}
+
+// Width returns the number of columns s takes up in a terminal using a fixed
+// width font. East Asian Wide and Fullwidth characters take two columns, marks
+// and other zero width characters none, everything else one. Characters whose
+// width is Ambiguous are counted as one column, the way most terminals outside
+// of East Asia draw them
+/*
+utilities.Width("Hello, 世界") == 11
+*/
+func Width(s string) int {
+	width := 0
+	for g := range Graphemes(s) {
+		width += clusterWidth(g)
+	}
+	return width
+}
+
+// TruncateWidth shortens s to at most width columns. When s has to be cut,
+// tail is put at the end and counts towards the width, a wide character that
+// would stick out past the limit is left out completely. A width of 0 or less
+// gives ""
+/*
+utilities.TruncateWidth("Hello, 世界", 9, "…") == "Hello, …"
+*/
+func TruncateWidth(s string, width int, tail string) string {
+	if width <= 0 {
+		return ""
+	}
+	if Width(s) <= width {
+		return s
+	}
+	room := width - Width(tail)
+	if room < 0 {
+		// Not even the tail fits
+		return TruncateWidth(tail, width, "")
+	}
+
+	used, end := 0, 0
+	for g := range Graphemes(s) {
+		w := clusterWidth(g)
+		if used+w > room {
+			break
+		}
+		used += w
+		end += len(g)
+	}
+	return s[:end] + tail
+}
+
+// PadWidth adds spaces to the end of s until it is width columns wide, so that
+// columns of text line up. Text that is already as wide is returned as it is
+/*
+utilities.PadWidth("世界", 6) == "世界  "
+*/
+func PadWidth(s string, width int) string {
+	missing := width - Width(s)
+	if missing <= 0 {
+		return s
+	}
+	return s + strings.Repeat(" ", missing)
+}
+
+// CenterWidth adds spaces on both sides of s until it is width columns wide.
+// When the spaces cannot be split evenly the extra one goes to the right
+/*
+utilities.CenterWidth("世界", 7) == " 世界  "
+*/
+func CenterWidth(s string, width int) string {
+	missing := width - Width(s)
+	if missing <= 0 {
+		return s
+	}
+	left := missing / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", missing-left)
+}
@@ -0,0 +1,153 @@
+package utilities
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestWidth(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"Hello, world", 12},
+		{"Hello, 世界", 11},
+		// Fullwidth Ａ and the ideographic space U+3000
+		{"Ａ\u3000", 4},
+		// e followed by U+0301 COMBINING ACUTE ACCENT is one column
+		{"cafe\u0301", 4},
+		// Hangul written as conjoining jamo is a single wide syllable
+		{"\u1112\u1161\u11ab", 2},
+		// U+200B ZERO WIDTH SPACE and a newline take no room
+		{"a\u200bb\n", 2},
+		// U+2764 HEAVY BLACK HEART is narrow until U+FE0F asks for the emoji
+		{"❤", 1},
+		{"❤\ufe0f", 2},
+		// A flag and a family joined with U+200D ZERO WIDTH JOINER
+		{"\U0001F1EF\U0001F1F5", 2},
+		{"\U0001F468\u200d\U0001F469\u200d\U0001F467", 2},
+		// Ambiguous characters such as U+00B1 PLUS-MINUS SIGN count as narrow
+		{"±", 1},
+	}
+
+	for _, c := range cases {
+		got := Width(c.in)
+		if got != c.want {
+			t.Errorf("Width(%+q) == %d, want %d", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	cases := []struct {
+		in    string
+		width int
+		tail  string
+		want  string
+	}{
+		{"Hello, 世界", 11, "…", "Hello, 世界"},
+		{"Hello, 世界", 10, "…", "Hello, 世…"},
+		// The 世 would stick out by one column so it goes completely
+		{"Hello, 世界", 9, "…", "Hello, …"},
+		{"Hello, 世界", 8, "", "Hello, "},
+		{"世界", 3, "", "世"},
+		// Marks stay with their letter
+		{"e\u0301e\u0301e\u0301", 2, "", "e\u0301e\u0301"},
+		{"abc", 1, "...", "."},
+		{"abc", 0, "…", ""},
+		// A negative width used to recurse until the stack ran out
+		{"abc", -1, "…", ""},
+		{"", -1, "", ""},
+	}
+
+	for _, c := range cases {
+		got := TruncateWidth(c.in, c.width, c.tail)
+		if got != c.want {
+			t.Errorf("TruncateWidth(%q, %d, %q) == %q, want %q", c.in, c.width, c.tail, got, c.want)
+		}
+	}
+}
+
+func TestPadWidth(t *testing.T) {
+	cases := []struct {
+		in     string
+		width  int
+		pad    string
+		center string
+	}{
+		{"世界", 6, "世界  ", " 世界 "},
+		{"世界", 7, "世界   ", " 世界  "},
+		{"abc", 2, "abc", "abc"},
+		{"", 2, "  ", "  "},
+	}
+
+	for _, c := range cases {
+		if got := PadWidth(c.in, c.width); got != c.pad {
+			t.Errorf("PadWidth(%q, %d) == %q, want %q", c.in, c.width, got, c.pad)
+		}
+		if got := CenterWidth(c.in, c.width); got != c.center {
+			t.Errorf("CenterWidth(%q, %d) == %q, want %q", c.in, c.width, got, c.center)
+		}
+	}
+}
+
+// Checks the generated table against the data file in testdata, every code
+// point the file gives as Wide or Fullwidth must take two columns and no other
+func TestWidthTable(t *testing.T) {
+	f, err := os.Open("testdata/EastAsianWidth.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	values := make([]string, unicode.MaxRune+1)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 && text != "# EastAsianWidth-"+widthUnicodeVersion+".txt" {
+			t.Fatalf("the file starts with %q, want version %s", text, widthUnicodeVersion)
+		}
+		// The defaults of the code points the file does not list come first
+		if missing, found := strings.CutPrefix(text, "# @missing:"); found {
+			text = missing
+		} else {
+			text, _, _ = strings.Cut(text, "#")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields := strings.Split(text, ";")
+		if len(fields) != 2 {
+			t.Fatalf("line %d: want 2 fields, got %d", line, len(fields))
+		}
+		first, last, found := strings.Cut(strings.TrimSpace(fields[0]), "..")
+		if !found {
+			last = first
+		}
+		lo, err := strconv.ParseUint(first, 16, 32)
+		if err != nil {
+			t.Fatalf("line %d: %v", line, err)
+		}
+		hi, err := strconv.ParseUint(last, 16, 32)
+		if err != nil {
+			t.Fatalf("line %d: %v", line, err)
+		}
+		for c := lo; c <= hi; c++ {
+			values[c] = strings.TrimSpace(fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for c, value := range values {
+		if want := value == "W" || value == "F"; isWide(rune(c)) != want {
+			t.Errorf("isWide(%U) == %v, want %v for East_Asian_Width %s", c, !want, want, value)
+		}
+	}
+}
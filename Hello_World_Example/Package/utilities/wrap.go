@@ -0,0 +1,401 @@
+package utilities
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WrapStrategy picks how Wrap decides where the lines break
+type WrapStrategy int
+
+const (
+	// WrapGreedy puts as many words on every line as fit, it is the fastest
+	// and the way most editors wrap text
+	WrapGreedy WrapStrategy = iota
+	// WrapMinRaggedness looks at the whole paragraph and picks the breaks that
+	// keep the space left at the end of the lines, squared, as small as possible
+	WrapMinRaggedness
+	// WrapKnuthPlass is the line breaking of TeX. Spaces stretch, every line
+	// gets a badness for how far they have to and lines of very different
+	// tightness next to each other cost extra. It gives the best looking
+	// paragraphs when they are justified
+	WrapKnuthPlass
+)
+
+// WrapOptions says how Wrap lays out the text
+type WrapOptions struct {
+	// Width is the number of terminal columns every line may take up,
+	// indents included. With 0 or less the lines are not wrapped at all, only
+	// their spaces are tidied up and the indent is put in front
+	Width int
+	// Strategy picks where the lines break
+	Strategy WrapStrategy
+	// Justify adds spaces between the words of every line but the last one
+	// of a paragraph, so that the lines end in the same column
+	Justify bool
+	// Indent is put in front of the first line of every paragraph
+	Indent string
+	// HangingIndent is put in front of all the other lines
+	HangingIndent string
+}
+
+// A piece of text that is never broken across lines
+type wrapWord struct {
+	text  string
+	width int
+	// The glue in front of the word, 1 for a space and 0 for a break between
+	// two CJK characters
+	gap int
+}
+
+// CJK punctuation that may not start a line, and the one that may not end one
+const (
+	wrapNoBreakBefore = "、。，．：；！？）」』】〕〉》〙〗ー々ゝゞ…‥"
+	wrapNoBreakAfter  = "（「『【〔〈《〘〖"
+)
+
+// Wrap breaks s into lines of at most opts.Width columns, measured with Width.
+// Lines break at tabs, at the spaces of Unicode such as U+3000 IDEOGRAPHIC
+// SPACE and between CJK characters, never at a no-break space such as U+00A0.
+// Every line of s is a paragraph of its own, runs of spaces inside of it
+// count as a single space. A word longer than a line is
+// split between its grapheme clusters
+/*
+utilities.Wrap("The quick brown fox", utilities.WrapOptions{Width: 10}) == "The quick\nbrown fox"
+*/
+func Wrap(s string, opts WrapOptions) string {
+	paragraphs := strings.Split(s, "\n")
+	out := make([]string, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		out = append(out, wrapParagraph(paragraph, opts)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Wraps a single paragraph into lines
+func wrapParagraph(paragraph string, opts WrapOptions) []string {
+	if opts.Width <= 0 {
+		words := wrapWords(paragraph, math.MaxInt)
+		if len(words) == 0 {
+			return []string{""}
+		}
+		var b strings.Builder
+		b.WriteString(opts.Indent)
+		writeLine(&b, words, 0)
+		return []string{b.String()}
+	}
+	first := max(opts.Width-Width(opts.Indent), 1)
+	rest := max(opts.Width-Width(opts.HangingIndent), 1)
+	words := wrapWords(paragraph, min(first, rest))
+	if len(words) == 0 {
+		return []string{""}
+	}
+	limit := func(start int) int {
+		if start == 0 {
+			return first
+		}
+		return rest
+	}
+
+	var breaks []int
+	switch opts.Strategy {
+	case WrapMinRaggedness:
+		breaks = wrapMinRaggedness(words, limit)
+	case WrapKnuthPlass:
+		breaks = wrapKnuthPlass(words, limit)
+	default:
+		breaks = wrapGreedy(words, limit)
+	}
+
+	lines := make([]string, 0, len(breaks))
+	start := 0
+	for n, end := range breaks {
+		var b strings.Builder
+		if start == 0 {
+			b.WriteString(opts.Indent)
+		} else {
+			b.WriteString(opts.HangingIndent)
+		}
+		extra := 0
+		if opts.Justify && n < len(breaks)-1 {
+			extra = limit(start) - lineWidth(words[start:end])
+		}
+		writeLine(&b, words[start:end], extra)
+		lines = append(lines, b.String())
+		start = end
+	}
+	return lines
+}
+
+// Splits a paragraph into words, a word is never wider than limit
+func wrapWords(paragraph string, limit int) []wrapWord {
+	var words []wrapWord
+	var current strings.Builder
+	width, gap := 0, 0
+	previous := ""
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, wrapWord{current.String(), width, gap})
+			current.Reset()
+			width, gap = 0, 0
+		}
+	}
+
+	for g := range Graphemes(paragraph) {
+		if isWrapSpace(g) {
+			flush()
+			if len(words) > 0 {
+				gap = 1
+			}
+			previous = ""
+			continue
+		}
+		w := clusterWidth(g)
+		// CJK text may break between any two characters
+		if current.Len() > 0 && wrapCanBreak(previous, g) {
+			flush()
+		}
+		// Words that do not fit on a line are cut
+		if current.Len() > 0 && width+w > limit {
+			flush()
+		}
+		current.WriteString(g)
+		width += w
+		previous = g
+	}
+	flush()
+	return words
+}
+
+// Reports whether the cluster g is a space a line may break at, a tab or a
+// space of Unicode that is not a no-break space
+func isWrapSpace(g string) bool {
+	r, size := utf8.DecodeRuneInString(g)
+	if size != len(g) {
+		return false
+	}
+	switch r {
+	case '\t':
+		return true
+	case '\u00a0', '\u2007', '\u202f':
+		return false
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+// Reports whether a line may break between the clusters a and b without a space
+func wrapCanBreak(a, b string) bool {
+	if clusterWidth(a) != 2 && clusterWidth(b) != 2 {
+		return false
+	}
+	return !strings.Contains(wrapNoBreakAfter, a) && !strings.Contains(wrapNoBreakBefore, b)
+}
+
+// Returns the natural width of a line holding words
+func lineWidth(words []wrapWord) int {
+	width := 0
+	for i, w := range words {
+		if i > 0 {
+			width += w.gap
+		}
+		width += w.width
+	}
+	return width
+}
+
+// Writes words separated by their gaps, spreading extra spaces over the gaps.
+// When there are no spaces between the words, as in CJK text, the extra spaces
+// go between the characters instead
+func writeLine(b *strings.Builder, words []wrapWord, extra int) {
+	stretchable := 0
+	for _, w := range words[1:] {
+		stretchable += w.gap
+	}
+	spaceGaps := stretchable > 0
+	if !spaceGaps {
+		stretchable = len(words) - 1
+	}
+
+	k := 0
+	for i, w := range words {
+		if i > 0 {
+			spaces := w.gap
+			if (spaceGaps && w.gap > 0) || !spaceGaps {
+				// The first gaps take the spaces that do not divide evenly
+				if stretchable > 0 && extra > 0 {
+					spaces += extra / stretchable
+					if k < extra%stretchable {
+						spaces++
+					}
+				}
+				k++
+			}
+			b.WriteString(strings.Repeat(" ", spaces))
+		}
+		b.WriteString(w.text)
+	}
+}
+
+// Fills every line as far as it goes. Returns the index after the last word
+// of every line
+func wrapGreedy(words []wrapWord, limit func(start int) int) []int {
+	var breaks []int
+	start, width := 0, words[0].width
+	for i := 1; i < len(words); i++ {
+		if width+words[i].gap+words[i].width > limit(start) {
+			breaks = append(breaks, i)
+			start, width = i, words[i].width
+			continue
+		}
+		width += words[i].gap + words[i].width
+	}
+	return append(breaks, len(words))
+}
+
+// Finds the cheapest way to break the words into lines, cost returns what a line
+// of words[start:end] costs or false when it does not fit. The result has the
+// index after the last word of every line
+func wrapCheapest(words []wrapWord, cost func(start, end int) (float64, bool)) []int {
+	n := len(words)
+	best := make([]float64, n+1)
+	from := make([]int, n+1)
+	for end := 1; end <= n; end++ {
+		best[end] = math.Inf(1)
+		// Lines only get wider as they start earlier, so after the first line
+		// that does not fit only the first line of the paragraph is left to
+		// try, which may have more room than the others
+		for start := end - 1; start >= 0; start-- {
+			c, ok := cost(start, end)
+			if !ok {
+				start = min(start, 1)
+				continue
+			}
+			if total := best[start] + c; total < best[end] {
+				best[end], from[end] = total, start
+			}
+		}
+	}
+
+	var breaks []int
+	for end := n; end > 0; end = from[end] {
+		breaks = append(breaks, end)
+	}
+	ReverseSlice(breaks)
+	return breaks
+}
+
+// Keeps the sum of the squares of the space left at the end of every line but
+// the last as small as possible
+func wrapMinRaggedness(words []wrapWord, limit func(start int) int) []int {
+	return wrapCheapest(words, func(start, end int) (float64, bool) {
+		left := limit(start) - lineWidth(words[start:end])
+		if left < 0 {
+			// A single word always fits since wrapWords cut it to size
+			return 0, end-start == 1
+		}
+		if end == len(words) {
+			return 0, true
+		}
+		return float64(left * left), true
+	})
+}
+
+// The constants TeX uses for its demerits
+const (
+	knuthPlassLinePenalty = 10
+	knuthPlassAdjacent    = 3000
+)
+
+// Returns the fitness class of a line that has to stretch by ratio, lines of
+// classes more than one apart should not follow each other
+func knuthPlassFitness(ratio float64) int {
+	switch {
+	case ratio > 1:
+		return 0 // Very loose
+	case ratio > 0.5:
+		return 1 // Loose
+	}
+	return 2 // Decent, spaces never shrink so there are no tight lines
+}
+
+// The line breaking of Knuth and Plass. Every space between words stretches, or
+// every break between CJK characters when there are no spaces. A line that
+// stretches by r columns per gap has a badness of 100r³ and costs
+// (10 + badness)² demerits, with 3000 more when its fitness class is far from
+// that of the line before it. The last line of a paragraph does not have to
+// fill its width. Returns the index after the last word of every line
+func wrapKnuthPlass(words []wrapWord, limit func(start int) int) []int {
+	n := len(words)
+	const classes = 3
+	// best[i][c] is the cheapest way to break before word i with the line
+	// ending there in fitness class c
+	type state struct {
+		demerits float64
+		from     int
+		class    int
+	}
+	best := make([][classes]state, n+1)
+	for i := range best {
+		for c := range classes {
+			best[i][c].demerits = math.Inf(1)
+		}
+	}
+	best[0][2].demerits = 0
+
+	for end := 1; end <= n; end++ {
+		for start := end - 1; start >= 0; start-- {
+			natural := lineWidth(words[start:end])
+			room := limit(start)
+			if natural > room && end-start > 1 {
+				// As in wrapCheapest only the first line may still fit
+				start = min(start, 1)
+				continue
+			}
+
+			badness, class := 0.0, 2
+			if end < n {
+				// The same gaps writeLine spreads the spaces over
+				stretch := 0
+				for _, w := range words[start+1 : end] {
+					stretch += w.gap
+				}
+				// A single word has nothing to stretch, it counts as if one
+				// gap had to fill the whole line
+				stretch = max(stretch, end-start-1, 1)
+				if natural < room {
+					ratio := float64(room-natural) / float64(stretch)
+					badness = 100 * ratio * ratio * ratio
+					class = knuthPlassFitness(ratio)
+				}
+			}
+			demerits := (knuthPlassLinePenalty + badness) * (knuthPlassLinePenalty + badness)
+
+			for previous := range classes {
+				total := best[start][previous].demerits + demerits
+				if start > 0 && abs(previous-class) > 1 {
+					total += knuthPlassAdjacent
+				}
+				if total < best[end][class].demerits {
+					best[end][class] = state{total, start, previous}
+				}
+			}
+		}
+	}
+
+	class := 0
+	for c := range classes {
+		if best[n][c].demerits < best[n][class].demerits {
+			class = c
+		}
+	}
+	var breaks []int
+	for end := n; end > 0; {
+		breaks = append(breaks, end)
+		s := best[end][class]
+		end, class = s.from, s.class
+	}
+	ReverseSlice(breaks)
+	return breaks
+}
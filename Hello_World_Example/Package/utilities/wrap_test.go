@@ -0,0 +1,109 @@
+package utilities
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts WrapOptions
+		want string
+	}{
+		{"", WrapOptions{Width: 10}, ""},
+		{"The quick brown fox", WrapOptions{Width: 10}, "The quick\nbrown fox"},
+		// Runs of spaces count as one and every line of the input is a paragraph
+		{"one   two\n\nthree", WrapOptions{Width: 20}, "one two\n\nthree"},
+		// Greedy fills the first line, the others even the lines out
+		{"aaa bb cc ddddd", WrapOptions{Width: 6}, "aaa bb\ncc\nddddd"},
+		{"aaa bb cc ddddd", WrapOptions{Width: 6, Strategy: WrapMinRaggedness}, "aaa\nbb cc\nddddd"},
+		// Words longer than a line are cut
+		{"abcdefghij", WrapOptions{Width: 4}, "abcd\nefgh\nij"},
+		// CJK breaks between characters and counts two columns for each
+		{"Hello, 世界你好", WrapOptions{Width: 8}, "Hello,\n世界你好"},
+		{"世界你好世界", WrapOptions{Width: 5}, "世界\n你好\n世界"},
+		// 。 may not start a line
+		{"世界。你好", WrapOptions{Width: 4}, "世\n界。\n你好"},
+		// U+00A0 NO-BREAK SPACE keeps its words together
+		{"a b\u00a0c", WrapOptions{Width: 3}, "a\nb\u00a0c"},
+		// U+3000 IDEOGRAPHIC SPACE and the other spaces of Unicode break lines
+		{"one\u3000two\u2003three", WrapOptions{Width: 8}, "one two\nthree"},
+		{"a\u2007b c", WrapOptions{Width: 3}, "a\u2007b\nc"},
+		// Without a width nothing is wrapped
+		{"hello  world", WrapOptions{}, "hello world"},
+		{"hello world\n\nagain", WrapOptions{Width: -1, Indent: "> "}, "> hello world\n\n> again"},
+		// Hanging indents
+		{"-v print every step", WrapOptions{Width: 10, HangingIndent: "   "}, "-v print\n   every\n   step"},
+		{"one two three", WrapOptions{Width: 9, Indent: "* ", HangingIndent: "  "}, "* one two\n  three"},
+		// A first line with more room than the others takes all it can hold
+		{"aa bb cc", WrapOptions{Width: 10, HangingIndent: "      "}, "aa bb cc"},
+		{"aa bb cc", WrapOptions{Width: 10, HangingIndent: "      ", Strategy: WrapMinRaggedness}, "aa bb cc"},
+		{"aa bb cc", WrapOptions{Width: 10, HangingIndent: "      ", Strategy: WrapKnuthPlass}, "aa bb cc"},
+	}
+
+	for _, c := range cases {
+		got := Wrap(c.in, c.opts)
+		if got != c.want {
+			t.Errorf("Wrap(%q, %+v) == %q, want %q", c.in, c.opts, got, c.want)
+		}
+	}
+}
+
+func TestWrapJustify(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts WrapOptions
+		want string
+	}{
+		{"a bb ccc dd e", WrapOptions{Width: 10, Justify: true}, "a  bb  ccc\ndd e"},
+		// The first gaps take the spaces that do not divide evenly
+		{"a b c d e f", WrapOptions{Width: 8, Justify: true}, "a  b c d\ne f"},
+		// CJK lines without spaces are spread between their characters
+		{"世界你好世界", WrapOptions{Width: 7, Justify: true}, "世 界你\n好世界"},
+		{"- one two three four", WrapOptions{Width: 10, Justify: true, HangingIndent: "  "}, "-  one two\n  three\n  four"},
+	}
+
+	for _, c := range cases {
+		got := Wrap(c.in, c.opts)
+		if got != c.want {
+			t.Errorf("Wrap(%q, %+v) == %q, want %q", c.in, c.opts, got, c.want)
+		}
+	}
+
+	// Every line but the last ends in the same column whatever the strategy
+	text := "Far out in the uncharted backwaters of the unfashionable end of the western spiral arm of the Galaxy lies a small unregarded yellow sun."
+	for _, strategy := range []WrapStrategy{WrapGreedy, WrapMinRaggedness, WrapKnuthPlass} {
+		lines := strings.Split(Wrap(text, WrapOptions{Width: 30, Strategy: strategy, Justify: true}), "\n")
+		for i, line := range lines {
+			if w := Width(line); (i < len(lines)-1 && w != 30) || w > 30 {
+				t.Errorf("strategy %d line %d %q is %d columns wide", strategy, i, line, w)
+			}
+		}
+		if got := strings.Join(strings.Fields(strings.Join(lines, " ")), " "); got != text {
+			t.Errorf("strategy %d changed the words: %q", strategy, got)
+		}
+	}
+}
+
+// The strategies that look at the whole paragraph never leave more raggedness
+// than the greedy one
+func TestWrapMinRaggedness(t *testing.T) {
+	raggedness := func(s string, width int) int {
+		lines := strings.Split(s, "\n")
+		total := 0
+		for _, line := range lines[:len(lines)-1] {
+			left := width - Width(line)
+			total += left * left
+		}
+		return total
+	}
+	text := "It was the best of times, it was the worst of times, it was the age of wisdom, it was the age of foolishness"
+	for width := 12; width <= 40; width += 7 {
+		greedy := raggedness(Wrap(text, WrapOptions{Width: width}), width)
+		minimum := raggedness(Wrap(text, WrapOptions{Width: width, Strategy: WrapMinRaggedness}), width)
+		if minimum > greedy {
+			t.Errorf("width %d: minimum raggedness %d is worse than greedy %d", width, minimum, greedy)
+		}
+	}
+}
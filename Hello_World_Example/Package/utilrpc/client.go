@@ -0,0 +1,100 @@
+package utilrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+)
+
+// DefaultTimeout is how long a call may take when its context has no deadline
+const DefaultTimeout = 30 * time.Second
+
+// Client calls a utilrpc server. It is safe to use from many goroutines at
+// once, their calls share the connection
+/*
+c, err := utilrpc.Dial(ctx, "localhost:7070")
+if err != nil {
+	return err
+}
+defer c.Close()
+reversed, err := c.Reverse(ctx, "Hello, 世界")
+*/
+type Client struct {
+	rpc *rpc.Client
+	// Timeout is used instead of DefaultTimeout when it is above 0
+	Timeout time.Duration
+}
+
+// Dial connects to the server at address, a TCP address or "unix:" and the
+// path of a socket. ctx only limits how long connecting may take
+func Dial(ctx context.Context, address string) (*Client, error) {
+	var d net.Dialer
+	network, addr := splitAddress(address)
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a client that talks to a server over conn
+func NewClient(conn io.ReadWriteCloser) *Client {
+	return &Client{rpc: jsonrpc.NewClient(conn)}
+}
+
+// Close closes the connection, calls in flight fail with rpc.ErrShutdown
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Reverse returns text reversed rune by rune by the server
+func (c *Client) Reverse(ctx context.Context, text string) (string, error) {
+	reply, err := call[TextReply](ctx, c, "Utilities.Reverse", &TextArgs{Text: text})
+	return reply.Text, err
+}
+
+// Apply returns the result of the function called name, one of Functions on
+// the server, for text
+func (c *Client) Apply(ctx context.Context, name, text string) (string, error) {
+	reply, err := call[TextReply](ctx, c, "Utilities.Apply", &ApplyArgs{Func: name, Text: text})
+	return reply.Text, err
+}
+
+// Functions returns the names the server takes in Apply
+func (c *Client) Functions(ctx context.Context) ([]string, error) {
+	return call[[]string](ctx, c, "Utilities.Functions", &Empty{})
+}
+
+// Makes a call that gives up when ctx is done or its deadline passes. An
+// abandoned call still gets its answer from the server, which is then thrown
+// away, so every call decodes into a reply of its own
+func call[R any](ctx context.Context, c *Client, method string, args any) (R, error) {
+	var reply R
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := c.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return reply, err
+	}
+
+	result := new(R)
+	done := c.rpc.Go(method, args, result, make(chan *rpc.Call, 1)).Done
+	select {
+	case call := <-done:
+		if call.Error != nil {
+			return reply, call.Error
+		}
+		return *result, nil
+	case <-ctx.Done():
+		return reply, ctx.Err()
+	}
+}
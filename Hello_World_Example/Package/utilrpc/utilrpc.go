@@ -0,0 +1,188 @@
+// Package utilrpc serves the string functions of the utilities package over
+// JSON-RPC 1.0, as spoken by net/rpc/jsonrpc, so that programs in other
+// languages can call them, and has a typed Go client for them
+/*
+Every request is one JSON object on the connection, the reply comes back with
+the same id:
+
+	{"method": "Utilities.Reverse", "params": [{"text": "Hello"}], "id": 1}
+	{"id": 1, "result": {"text": "olleH"}, "error": null}
+
+	{"method": "Utilities.Apply", "params": [{"func": "ToSnake", "text": "HTTPServer"}], "id": 2}
+	{"id": 2, "result": {"text": "http_server"}, "error": null}
+
+	{"method": "Utilities.Functions", "params": [{}], "id": 3}
+	{"id": 3, "result": ["NFC", "NFD", ...], "error": null}
+*/
+package utilrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities"
+)
+
+// Functions holds every function that Utilities.Apply can call by name. A new
+// function of the utilities package that takes and returns a string only has
+// to be added here to be served
+var Functions = map[string]func(string) string{
+	"Reverse":          utilities.Reverse,
+	"ReverseGraphemes": utilities.ReverseGraphemes,
+	"ReverseWords":     utilities.ReverseWords,
+	"ReverseLines":     utilities.ReverseLines,
+	"ToSnake":          utilities.ToSnake,
+	"ToKebab":          utilities.ToKebab,
+	"ToCamel":          utilities.ToCamel,
+	"ToPascal":         utilities.ToPascal,
+	"ToTitle":          utilities.ToTitle,
+	"Slugify":          utilities.Slugify,
+	"NFC":              func(s string) string { return utilities.Normalize(utilities.NFC, s) },
+	"NFD":              func(s string) string { return utilities.Normalize(utilities.NFD, s) },
+	"NFKC":             func(s string) string { return utilities.Normalize(utilities.NFKC, s) },
+	"NFKD":             func(s string) string { return utilities.Normalize(utilities.NFKD, s) },
+}
+
+// TextArgs is the argument of Utilities.Reverse
+type TextArgs struct {
+	Text string `json:"text"`
+}
+
+// ApplyArgs is the argument of Utilities.Apply, Func is a key of Functions
+type ApplyArgs struct {
+	Func string `json:"func"`
+	Text string `json:"text"`
+}
+
+// TextReply is the result of Utilities.Reverse and Utilities.Apply
+type TextReply struct {
+	Text string `json:"text"`
+}
+
+// Empty is the argument of calls that need none
+type Empty struct{}
+
+// Utilities is the receiver whose methods are served. The methods follow the
+// rules of net/rpc and are not meant to be called directly
+type Utilities struct{}
+
+// Reverse reverses args.Text rune by rune with utilities.Reverse
+func (Utilities) Reverse(args *TextArgs, reply *TextReply) error {
+	reply.Text = utilities.Reverse(args.Text)
+	return nil
+}
+
+// Apply calls the function of Functions called args.Func with args.Text
+func (Utilities) Apply(args *ApplyArgs, reply *TextReply) error {
+	f, ok := Functions[args.Func]
+	if !ok {
+		return fmt.Errorf("utilrpc: unknown function %q", args.Func)
+	}
+	reply.Text = f(args.Text)
+	return nil
+}
+
+// Functions lists the names Apply takes in sorted order
+func (Utilities) Functions(_ *Empty, reply *[]string) error {
+	names := make([]string, 0, len(Functions))
+	for name := range Functions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	*reply = names
+	return nil
+}
+
+// NewServer returns an RPC server with Utilities registered under its name
+func NewServer() *rpc.Server {
+	server := rpc.NewServer()
+	if err := server.Register(Utilities{}); err != nil {
+		// Only happens when the methods above break the rules of net/rpc
+		panic(err)
+	}
+	return server
+}
+
+// Splits an address into the network and the address to give net.Listen and
+// net.Dial. "unix:/run/utilrpc.sock" is a Unix socket, "tcp:localhost:7070"
+// and "localhost:7070" are TCP
+func splitAddress(address string) (string, string) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", strings.TrimPrefix(address, "tcp:")
+}
+
+// Listen listens on address, a TCP address or "unix:" and the path of a socket
+/*
+ln, err := utilrpc.Listen("unix:/tmp/utilrpc.sock")
+*/
+func Listen(address string) (net.Listener, error) {
+	return net.Listen(splitAddress(address))
+}
+
+// Serve answers JSON-RPC calls on every connection accepted from ln until ctx
+// is done. Then it closes ln and every open connection, waits for the calls in
+// flight to return and returns nil
+func Serve(ctx context.Context, ln net.Listener) error {
+	server := NewServer()
+	var (
+		mu    sync.Mutex
+		conns = map[net.Conn]bool{}
+		wg    sync.WaitGroup
+	)
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for conn := range conns {
+			conn.Close()
+		}
+	})
+	defer stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil && errors.Is(err, net.ErrClosed) {
+				wg.Wait()
+				return nil
+			}
+			// Nothing will close the open connections now, and waiting for
+			// their clients to hang up could take forever
+			mu.Lock()
+			for conn := range conns {
+				conn.Close()
+			}
+			mu.Unlock()
+			wg.Wait()
+			return err
+		}
+		mu.Lock()
+		if ctx.Err() != nil {
+			// Shutdown started between Accept and here
+			mu.Unlock()
+			conn.Close()
+			continue
+		}
+		conns[conn] = true
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Returns once the client hangs up or the connection is closed
+			server.ServeCodec(jsonrpc.NewServerCodec(conn))
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+		}()
+	}
+}
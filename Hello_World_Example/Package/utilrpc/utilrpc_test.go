@@ -0,0 +1,182 @@
+package utilrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Starts a server on address and returns a client connected to it
+func startServer(t *testing.T, address string) *Client {
+	t.Helper()
+	ln, err := Listen(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, ln)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("Serve == %v, want nil", err)
+		}
+	})
+
+	network, addr := "tcp", ln.Addr().String()
+	if ln.Addr().Network() == "unix" {
+		network = "unix"
+	}
+	c, err := Dial(context.Background(), network+":"+addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestLoopback(t *testing.T) {
+	addresses := []string{"127.0.0.1:0", "unix:" + filepath.Join(t.TempDir(), "utilrpc.sock")}
+	for _, address := range addresses {
+		client := startServer(t, address)
+		ctx := context.Background()
+
+		if got, err := client.Reverse(ctx, "Hello, 世界"); err != nil || got != "界世 ,olleH" {
+			t.Errorf("%s: Reverse == %q, %v, want %q", address, got, err, "界世 ,olleH")
+		}
+		cases := []struct {
+			name, text, want string
+		}{
+			{"Reverse", "abc", "cba"},
+			{"ToSnake", "HTTPServer", "http_server"},
+			{"ReverseWords", "Hello, world!", "world! Hello,"},
+			{"Slugify", "Hello, World!", "hello-world"},
+			{"NFC", "e\u0301", "\u00e9"},
+		}
+		for _, c := range cases {
+			if got, err := client.Apply(ctx, c.name, c.text); err != nil || got != c.want {
+				t.Errorf("%s: Apply(%q, %q) == %q, %v, want %q", address, c.name, c.text, got, err, c.want)
+			}
+		}
+		if _, err := client.Apply(ctx, "Explode", "abc"); err == nil || err.Error() != `utilrpc: unknown function "Explode"` {
+			t.Errorf("%s: Apply of an unknown function == %v", address, err)
+		}
+		names, err := client.Functions(ctx)
+		if err != nil || !slices.IsSorted(names) || len(names) != len(Functions) {
+			t.Errorf("%s: Functions == %q, %v", address, names, err)
+		}
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	release := make(chan struct{})
+	Functions["Slow"] = func(s string) string {
+		<-release
+		return s
+	}
+	// Cleanups run last to first, so this one runs once the server is gone
+	t.Cleanup(func() { delete(Functions, "Slow") })
+	c := startServer(t, "127.0.0.1:0")
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Apply(ctx, "Slow", "abc"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Apply past its deadline == %v, want %v", err, context.DeadlineExceeded)
+	}
+	c.Timeout = 50 * time.Millisecond
+	if _, err := c.Apply(context.Background(), "Slow", "abc"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Apply past Timeout == %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The connection is still good for other calls
+	if got, err := c.Reverse(context.Background(), "abc"); err != nil || got != "cba" {
+		t.Errorf("Reverse after a timeout == %q, %v", got, err)
+	}
+}
+
+func TestServeShutdown(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, ln)
+	}()
+	c, err := Dial(context.Background(), ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Reverse(context.Background(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("Serve == %v, want nil", err)
+	}
+	// The open connection was closed too
+	if _, err := c.Reverse(context.Background(), "abc"); err == nil {
+		t.Errorf("Reverse after shutdown succeeded")
+	}
+	if _, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		t.Errorf("The server still listens after shutdown")
+	}
+}
+
+// Hands out one connection, then fails once fail is closed
+type failingListener struct {
+	net.Listener
+	accepted bool
+	fail     chan struct{}
+}
+
+func (l *failingListener) Accept() (net.Conn, error) {
+	if !l.accepted {
+		l.accepted = true
+		return l.Listener.Accept()
+	}
+	<-l.fail
+	return nil, errors.New("accept failed")
+}
+
+func TestServeAcceptError(t *testing.T) {
+	inner, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inner.Close()
+	ln := &failingListener{Listener: inner, fail: make(chan struct{})}
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(context.Background(), ln)
+	}()
+	c, err := Dial(context.Background(), inner.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Reverse(context.Background(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The client keeps its connection open, Serve still has to return
+	close(ln.fail)
+	select {
+	case err := <-served:
+		if err == nil || err.Error() != "accept failed" {
+			t.Errorf("Serve == %v, want the error of Accept", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Accept failed")
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// The message catalogs are compiled into the binary with the embed package, so
+// the command works from any directory. Every file in locales is named after
+// its locale, such as pt_BR.json, and maps a message key to its text
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// The locale used when nothing better is found, its catalog has every message
+const defaultLocale = "en"
+
+// A message is either plain text or, for text that holds a count, one text for
+// every plural category the language needs, such as "one" and "other"
+/*
+"hello": "Hello, world.",
+"files": {"one": "Wrote {count} file.", "other": "Wrote {count} files."}
+*/
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &m.plural)
+}
+
+// Returns the text of m for the plural category, "other" is used when the
+// category is missing
+func (m message) form(category string) string {
+	if m.plural == nil {
+		return m.text
+	}
+	if text, ok := m.plural[category]; ok {
+		return text
+	}
+	return m.plural["other"]
+}
+
+// All bundled catalogs by locale. The files are part of the source, so a
+// broken one is caught by the tests, and run reports catalogsErr before it
+// greets anyone
+var catalogs, catalogsErr = loadCatalogs(localeFiles)
+
+// Reads every catalog in the locales directory of fsys
+func loadCatalogs(fsys fs.FS) (map[string]map[string]message, error) {
+	names, err := fs.ReadDir(fsys, "locales")
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]map[string]message, len(names))
+	for _, entry := range names {
+		name := path.Join("locales", entry.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]message
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+	return out, nil
+}
+
+// Turns a locale as found in LANG, such as "pt_BR.UTF-8", "de_DE@euro" or
+// "pt-br", into the form the catalogs are named in, "pt_BR". The C and POSIX
+// locales are English
+func normalizeLocale(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return defaultLocale
+	}
+	language, region, found := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	if !found {
+		return strings.ToLower(language)
+	}
+	return strings.ToLower(language) + "_" + strings.ToUpper(region)
+}
+
+// Returns the bundled locales to look a message up in, the most specific first
+// and the default locale last
+/*
+localeChain("pt_BR") // [pt_BR pt en]
+localeChain("ja_JP") // [ja en]
+localeChain("xx")    // [en]
+*/
+func localeChain(locale string) []string {
+	var chain []string
+	locale = normalizeLocale(locale)
+	for locale != "" {
+		if _, ok := catalogs[locale]; ok {
+			chain = append(chain, locale)
+		}
+		i := strings.LastIndex(locale, "_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	if len(chain) == 0 || chain[len(chain)-1] != defaultLocale {
+		chain = append(chain, defaultLocale)
+	}
+	return chain
+}
+
+// Returns the locale the environment asks for. As in POSIX, LC_ALL wins over
+// LC_MESSAGES which wins over LANG
+func localeFromEnv(getenv func(string) string) string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Picks messages from the catalogs of a locale, falling back along its chain
+type localizer struct {
+	chain []string
+}
+
+func newLocalizer(locale string) localizer {
+	return localizer{chain: localeChain(locale)}
+}
+
+// Returns the message called key and the locale of the catalog it came from
+func (l localizer) lookup(key string) (message, string) {
+	for _, locale := range l.chain {
+		if m, ok := catalogs[locale][key]; ok {
+			return m, locale
+		}
+	}
+	// A missing message shows its key rather than nothing
+	return message{text: key}, defaultLocale
+}
+
+// Text returns the message called key with every {placeholder} replaced by
+// its value in vars
+/*
+l := newLocalizer("de_DE.UTF-8")
+l.Text("helloName", map[string]string{"name": "Ada"}) == "Hallo, Ada."
+*/
+func (l localizer) Text(key string, vars map[string]string) string {
+	m, _ := l.lookup(key)
+	return fill(m.form("other"), vars)
+}
+
+// Plural returns the form of the message called key that the language needs
+// for count. {count} is replaced by count and the other placeholders by their
+// value in vars. The plural rule is the one of the catalog the message came
+// from, so a message missing in Russian is written with English rules
+func (l localizer) Plural(key string, count int, vars map[string]string) string {
+	m, locale := l.lookup(key)
+	filled := map[string]string{"count": fmt.Sprint(count)}
+	for name, value := range vars {
+		filled[name] = value
+	}
+	return fill(m.form(pluralCategory(locale, count)), filled)
+}
+
+// Replaces every {name} in text with vars[name], unknown placeholders are kept
+func fill(text string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// The CLDR plural rules of the bundled languages for whole numbers, see
+// https://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+// A language without a rule only has "other"
+var pluralRules = map[string]func(n int) string{
+	"en": oneIfOne,
+	"de": oneIfOne,
+	"es": func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return manyIfMillions(n)
+	},
+	"fr": oneIfZeroOrOne,
+	"pt": oneIfZeroOrOne,
+	"ru": func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	},
+	"pl": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	},
+	"ar": func(n int) string {
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+		return "other"
+	},
+}
+
+func oneIfOne(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func oneIfZeroOrOne(n int) string {
+	if n == 0 || n == 1 {
+		return "one"
+	}
+	return manyIfMillions(n)
+}
+
+// Spanish, French and Portuguese say "un million de" for whole millions
+func manyIfMillions(n int) string {
+	if n != 0 && n%1000000 == 0 {
+		return "many"
+	}
+	return "other"
+}
+
+// Returns the plural category of n in the language of locale
+/*
+pluralCategory("ru", 3) == "few"
+pluralCategory("ru", 11) == "many"
+pluralCategory("ja", 1) == "other"
+*/
+func pluralCategory(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	if rule, ok := pluralRules[language]; ok {
+		return rule(n)
+	}
+	return "other"
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var placeholder = regexp.MustCompile(`\{[a-z]+\}`)
+
+// Every bundled locale must be able to say every English message, with the
+// plural forms its rule asks for and only the placeholders English has
+func TestCatalogs(t *testing.T) {
+	if catalogsErr != nil {
+		t.Fatal(catalogsErr)
+	}
+	english := catalogs[defaultLocale]
+	for locale := range catalogs {
+		l := newLocalizer(locale)
+		if l.chain[0] != locale {
+			t.Errorf("newLocalizer(%q) starts its chain with %q", locale, l.chain[0])
+		}
+		for key, want := range english {
+			m, from := l.lookup(key)
+			allowed := placeholder.FindAllString(strings.Join(formsOf(want), " "), -1)
+			for _, text := range formsOf(m) {
+				for _, p := range placeholder.FindAllString(text, -1) {
+					if !slices.Contains(allowed, p) {
+						t.Errorf("%s: %s has the unknown placeholder %s in %q", from, key, p, text)
+					}
+				}
+			}
+			if want.plural == nil {
+				continue
+			}
+			if m.plural["other"] == "" {
+				t.Errorf("%s: %s has no \"other\" form", from, key)
+			}
+			for _, n := range append(counts(), 1000000, 2000000) {
+				if category := pluralCategory(from, n); m.plural[category] == "" {
+					t.Errorf("%s: %s has no %q form for %d", from, key, category, n)
+				}
+			}
+		}
+	}
+	if got := newLocalizer("de").Text("helloName", map[string]string{"name": "Ada"}); got != "Hallo, Ada." {
+		t.Errorf("helloName in de == %q, want %q", got, "Hallo, Ada.")
+	}
+}
+
+func formsOf(m message) []string {
+	if m.plural == nil {
+		return []string{m.text}
+	}
+	var out []string
+	for _, text := range m.plural {
+		out = append(out, text)
+	}
+	return out
+}
+
+func counts() []int {
+	out := make([]int, 201)
+	for i := range out {
+		out[i] = i
+	}
+	return out
+}
+
+func TestPluralCategory(t *testing.T) {
+	cases := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 1, "one"},
+		{"en", 0, "other"},
+		{"en", -1, "one"},
+		{"fr", 0, "one"},
+		{"fr", 1000000, "many"},
+		{"pt", 1, "one"},
+		{"es", 0, "other"},
+		{"ru", 21, "one"},
+		{"ru", 11, "many"},
+		{"ru", 22, "few"},
+		{"ru", 12, "many"},
+		{"pl", 1, "one"},
+		{"pl", 21, "many"},
+		{"pl", 24, "few"},
+		{"ar", 0, "zero"},
+		{"ar", 2, "two"},
+		{"ar", 103, "few"},
+		{"ar", 111, "many"},
+		{"ar", 100, "other"},
+		{"ja", 1, "other"},
+		{"xx", 1, "other"},
+	}
+
+	for _, c := range cases {
+		if got := pluralCategory(c.locale, c.n); got != c.want {
+			t.Errorf("pluralCategory(%q, %d) == %q, want %q", c.locale, c.n, got, c.want)
+		}
+	}
+}
+
+func TestLoadCatalogsErrors(t *testing.T) {
+	cases := []struct {
+		fsys fstest.MapFS
+		want string
+	}{
+		{fstest.MapFS{"locales/en.json": {Data: []byte(`{"hello": }`)}}, "locales/en.json: invalid character '}' looking for beginning of value"},
+		{fstest.MapFS{"locales/en.json": {Data: []byte(`{"hello": 1}`)}}, "locales/en.json: json: cannot unmarshal number into Go value of type map[string]string"},
+		{fstest.MapFS{}, "open locales: file does not exist"},
+	}
+
+	for _, c := range cases {
+		if _, err := loadCatalogs(c.fsys); err == nil || err.Error() != c.want {
+			t.Errorf("loadCatalogs(%v) == %v, want %s", c.fsys, err, c.want)
+		}
+	}
+}
+
+// The bundled catalogs have no message with a count, so Plural is tested with
+// catalogs of its own
+var pluralCatalogs = fstest.MapFS{
+	"locales/en.json":    {Data: []byte(`{"reversed": {"one": "Reversed {count} character.", "other": "Reversed {count} characters."}}`)},
+	"locales/ru.json":    {Data: []byte(`{"reversed": {"one": "Перевёрнут {count} символ.", "few": "Перевёрнуто {count} символа.", "many": "Перевёрнуто {count} символов.", "other": "Перевёрнуто {count} символа."}}`)},
+	"locales/pl.json":    {Data: []byte(`{"reversed": {"one": "Odwrócono {count} znak.", "few": "Odwrócono {count} znaki.", "many": "Odwrócono {count} znaków.", "other": "Odwrócono {count} znaku."}}`)},
+	"locales/pt.json":    {Data: []byte(`{"reversed": {"one": "{count} caractere invertido.", "many": "{count} de caracteres invertidos.", "other": "{count} caracteres invertidos."}}`)},
+	"locales/pt_BR.json": {Data: []byte(`{}`)},
+	"locales/fr.json":    {Data: []byte(`{"reversed": {"one": "{count} caractère inversé.", "many": "{count} de caractères inversés.", "other": "{count} caractères inversés."}}`)},
+	"locales/ja.json":    {Data: []byte(`{}`)},
+}
+
+func TestPlural(t *testing.T) {
+	loaded, err := loadCatalogs(pluralCatalogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundled := catalogs
+	catalogs = loaded
+	t.Cleanup(func() { catalogs = bundled })
+
+	cases := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 1, "Reversed 1 character."},
+		{"en", 13, "Reversed 13 characters."},
+		{"ru", 12, "Перевёрнуто 12 символов."},
+		{"ru", 22, "Перевёрнуто 22 символа."},
+		{"pl_PL", 10, "Odwrócono 10 znaków."},
+		// pt_BR does not have the message, it comes from pt with the rules of pt
+		{"pt_BR", 0, "0 caractere invertido."},
+		{"fr", 2000000, "2000000 de caractères inversés."},
+		// ja does not have the message, it comes from en with the rules of en
+		{"ja", 1, "Reversed 1 character."},
+	}
+
+	for _, c := range cases {
+		if got := newLocalizer(c.locale).Plural("reversed", c.n, nil); got != c.want {
+			t.Errorf("Plural(%q, %d) in %s == %q, want %q", "reversed", c.n, c.locale, got, c.want)
+		}
+	}
+}
+
+func TestLocaleChain(t *testing.T) {
+	cases := []struct {
+		locale string
+		want   []string
+	}{
+		{"pt_BR", []string{"pt_BR", "pt", "en"}},
+		{"pt_BR.UTF-8", []string{"pt_BR", "pt", "en"}},
+		{"pt-br", []string{"pt_BR", "pt", "en"}},
+		{"pt_PT", []string{"pt", "en"}},
+		{"de_DE@euro", []string{"de", "en"}},
+		{"en_US.UTF-8", []string{"en"}},
+		{"C", []string{"en"}},
+		{"POSIX", []string{"en"}},
+		{"xx_YY", []string{"en"}},
+		{"", []string{"en"}},
+	}
+
+	for _, c := range cases {
+		if got := localeChain(c.locale); !slices.Equal(got, c.want) {
+			t.Errorf("localeChain(%q) == %q, want %q", c.locale, got, c.want)
+		}
+	}
+}
+
+func TestLocaleFromEnv(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"LANG": "de_DE.UTF-8"}, "de_DE.UTF-8"},
+		{map[string]string{"LANG": "de_DE", "LC_MESSAGES": "fr_FR"}, "fr_FR"},
+		{map[string]string{"LANG": "de_DE", "LC_MESSAGES": "fr_FR", "LC_ALL": "ja_JP"}, "ja_JP"},
+		{map[string]string{"LC_ALL": "", "LANG": "ru_RU"}, "ru_RU"},
+		{nil, ""},
+	}
+
+	for _, c := range cases {
+		getenv := func(name string) string { return c.env[name] }
+		if got := localeFromEnv(getenv); got != c.want {
+			t.Errorf("localeFromEnv(%v) == %q, want %q", c.env, got, c.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		args    []string
+		lang    string
+		wantOut string
+	}{
+		{nil, "", "Hello, world.\n.dlrow ,olleH\n"},
+		{nil, "de_DE.UTF-8", "Hallo, Welt.\n.tleW ,ollaH\n"},
+		{nil, "pt_BR.UTF-8", "Oi, mundo.\n.odnum ,iO\n"},
+		{[]string{"-lang", "ru"}, "de_DE", "Привет, мир.\n.рим ,тевирП\n"},
+		{[]string{"-lang", "ja", "-name", "Ada"}, "", "こんにちは、Adaさん。\n。んさadA、はちにんこ\n"},
+		{[]string{"-name", "Jo"}, "pl_PL", "Witaj, Jo.\n.oJ ,jatiW\n"},
+	}
+
+	for _, c := range cases {
+		var stdout, stderr bytes.Buffer
+		getenv := func(name string) string {
+			if name == "LANG" {
+				return c.lang
+			}
+			return ""
+		}
+		status := run(c.args, getenv, &stdout, &stderr)
+		if status != 0 || stdout.String() != c.wantOut {
+			t.Errorf("run(%q) with LANG=%q == %d, %q, want %q (stderr %q)",
+				c.args, c.lang, status, stdout.String(), c.wantOut, stderr.String())
+		}
+	}
+}
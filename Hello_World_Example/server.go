@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/utilities"
+)
+
+// The limits of the service. A slow or stuck client is cut off instead of
+// holding a connection forever, and a request body is never read whole past
+// maxBodySize
+const (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 10 * time.Second
+	writeTimeout      = 10 * time.Second
+	idleTimeout       = 60 * time.Second
+	// How long requests in flight get to finish once shutdown was asked for
+	shutdownTimeout = 10 * time.Second
+	maxBodySize     = 1 << 20
+)
+
+// Returns the handler of the service with every request logged to logger.
+// Greetings are in the language of the lang query parameter, then of the
+// Accept-Language header, then in locale
+/*
+GET /hello?name=Ada            Hello, Ada.
+GET /reverse?text=Hello        olleH
+POST /reverse with body Hello  olleH
+*/
+func newHandler(locale string, logger *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			httpError(w, r, http.StatusMethodNotAllowed, "only GET is allowed")
+			return
+		}
+		l := newLocalizer(requestLocale(r, locale))
+		greeting := l.Text("hello", nil)
+		if name := r.URL.Query().Get("name"); name != "" {
+			greeting = l.Text("helloName", map[string]string{"name": name})
+		}
+		respond(w, r, http.StatusOK, greeting, map[string]any{"greeting": greeting, "locale": l.chain[0]})
+	})
+	mux.HandleFunc("/reverse", func(w http.ResponseWriter, r *http.Request) {
+		var text string
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			text = r.URL.Query().Get("text")
+		case http.MethodPost:
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				httpError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("the text is longer than %d bytes", maxBodySize))
+				return
+			} else if err != nil {
+				httpError(w, r, http.StatusBadRequest, "the text could not be read")
+				return
+			}
+			text = string(body)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			httpError(w, r, http.StatusMethodNotAllowed, "only GET and POST are allowed")
+			return
+		}
+		reversed := utilities.Reverse(text)
+		respond(w, r, http.StatusOK, reversed, map[string]any{
+			"text":     text,
+			"reversed": reversed,
+			"runes":    utf8.RuneCountInString(text),
+		})
+	})
+	return logRequests(logger, mux)
+}
+
+// Returns the locale a request asks for, the lang query parameter wins over
+// the first language of Accept-Language
+func requestLocale(r *http.Request, fallback string) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return lang
+	}
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, q := parseQuality(part)
+		if tag != "" && tag != "*" && q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	if best == "" {
+		return fallback
+	}
+	return best
+}
+
+// Splits one entry of an Accept or Accept-Language header, such as
+// "application/json;q=0.8", into its value and its quality
+func parseQuality(part string) (string, float64) {
+	value, params, _ := strings.Cut(part, ";")
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		name, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if strings.EqualFold(name, "q") {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return strings.TrimSpace(value), q
+}
+
+// The quality a client gave a media type and how specific the range it came
+// from was, 3 for the type itself, 2 for type/* and 1 for */*
+type acceptQuality struct {
+	q        float64
+	specific int
+}
+
+// Takes q from a range of the given specificity when it is more specific than
+// the one seen so far
+func (a *acceptQuality) offer(q float64, specific int) {
+	if specific > a.specific || specific == a.specific && q > a.q {
+		*a = acceptQuality{q, specific}
+	}
+}
+
+// Reports whether the Accept header prefers JSON over plain text. The most
+// specific range that matches a type gives its quality, so application/json
+// wins over application/* whatever their order. Without a preference the
+// answer is plain text
+func wantsJSON(accept string) bool {
+	var textQ, jsonQ acceptQuality
+	for _, part := range strings.Split(accept, ",") {
+		value, q := parseQuality(part)
+		mediaType, _, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			jsonQ.offer(q, 3)
+		case "text/plain":
+			textQ.offer(q, 3)
+		case "application/*":
+			jsonQ.offer(q, 2)
+		case "text/*":
+			textQ.offer(q, 2)
+		case "*/*":
+			jsonQ.offer(q, 1)
+			textQ.offer(q, 1)
+		}
+	}
+	return jsonQ.q > textQ.q
+}
+
+// Writes text, or value as JSON when the client asks for it
+func respond(w http.ResponseWriter, r *http.Request, status int, text string, value any) {
+	if wantsJSON(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(value)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, text)
+}
+
+func httpError(w http.ResponseWriter, r *http.Request, status int, text string) {
+	respond(w, r, status, text, map[string]string{"error": text})
+}
+
+// Remembers the status a handler wrote, for the log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.size += n
+	return n, err
+}
+
+// Logs the method, path, status, size and duration of every request
+func logRequests(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.String("remote", r.RemoteAddr),
+			slog.String("method", r.Method),
+			slog.String("uri", r.URL.RequestURI()),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.size),
+			slog.Duration("duration", time.Since(start)))
+	})
+}
+
+// Serves handler on ln until ctx is done, then waits up to shutdownTimeout for
+// the requests in flight to finish. It returns nil after a clean shutdown
+func serve(ctx context.Context, ln net.Listener, handler http.Handler, logger *slog.Logger) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("shutting down", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
+)
+
+func TestHandler(t *testing.T) {
+	cases := []struct {
+		method, target string
+		header         map[string]string
+		body           string
+		wantStatus     int
+		wantType       string
+		wantBody       string
+	}{
+		{"GET", "/hello", nil, "", 200, "text/plain", "Hello, world.\n"},
+		{"GET", "/hello?name=Ada", nil, "", 200, "text/plain", "Hello, Ada.\n"},
+		{"GET", "/hello?name=Ada&lang=de", nil, "", 200, "text/plain", "Hallo, Ada.\n"},
+		{"GET", "/hello", map[string]string{"Accept-Language": "fr;q=0.5, pt-BR, en;q=0.8"}, "", 200, "text/plain", "Oi, mundo.\n"},
+		{"GET", "/hello", map[string]string{"Accept": "application/json"}, "", 200, "application/json",
+			`{"greeting":"Hello, world.","locale":"en"}` + "\n"},
+		{"POST", "/hello", nil, "", 405, "text/plain", "only GET is allowed\n"},
+		{"GET", "/reverse?text=Hello,+%E4%B8%96%E7%95%8C", nil, "", 200, "text/plain", "界世 ,olleH\n"},
+		{"POST", "/reverse", nil, "Hello", 200, "text/plain", "olleH\n"},
+		{"POST", "/reverse", map[string]string{"Accept": "text/plain;q=0.5, application/json"}, "añb", 200, "application/json",
+			`{"reversed":"bña","runes":3,"text":"añb"}` + "\n"},
+		// Plain text wins a tie and anything without JSON in it
+		{"GET", "/reverse?text=ab", map[string]string{"Accept": "application/json, text/plain"}, "", 200, "text/plain", "ba\n"},
+		{"GET", "/reverse?text=ab", map[string]string{"Accept": "*/*"}, "", 200, "text/plain", "ba\n"},
+		{"GET", "/reverse?text=ab", map[string]string{"Accept": "application/*"}, "", 200, "application/json",
+			`{"reversed":"ba","runes":2,"text":"ab"}` + "\n"},
+		// The most specific range gives the quality, whatever the order
+		{"GET", "/reverse?text=ab", map[string]string{"Accept": "application/json;q=0.1, application/*;q=0.9, text/plain;q=0.5"}, "", 200, "text/plain", "ba\n"},
+		{"GET", "/reverse?text=ab", map[string]string{"Accept": "text/*;q=0.9, application/json;q=0.8, */*;q=0.1"}, "", 200, "text/plain", "ba\n"},
+		{"GET", "/reverse?text=ab", map[string]string{"Accept": "*/*;q=0.5, application/json"}, "", 200, "application/json",
+			`{"reversed":"ba","runes":2,"text":"ab"}` + "\n"},
+		{"POST", "/reverse", nil, strings.Repeat("a", maxBodySize+1), 413, "text/plain", "the text is longer than 1048576 bytes\n"},
+		{"DELETE", "/reverse", map[string]string{"Accept": "application/json"}, "", 405, "application/json",
+			`{"error":"only GET and POST are allowed"}` + "\n"},
+		{"GET", "/nowhere", nil, "", 404, "text/plain", "404 page not found\n"},
+	}
+
+	recorder := logging.NewRecorder()
+	handler := newHandler("en_US.UTF-8", slog.New(recorder))
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+		for name, value := range c.header {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != c.wantStatus || !strings.HasPrefix(w.Header().Get("Content-Type"), c.wantType) || w.Body.String() != c.wantBody {
+			t.Errorf("%s %s == %d, %q, %q, want %d, %q, %q", c.method, c.target,
+				w.Code, w.Header().Get("Content-Type"), w.Body.String(), c.wantStatus, c.wantType, c.wantBody)
+		}
+	}
+
+	// Every request is logged with its outcome
+	records := recorder.Records()
+	if len(records) != len(cases) {
+		t.Fatalf("%d requests were logged, want %d", len(records), len(cases))
+	}
+	for i, c := range cases {
+		attrs := logging.AttrMap(records[i])
+		if attrs["method"].String() != c.method || attrs["uri"].String() != c.target || attrs["status"].Int64() != int64(c.wantStatus) {
+			t.Errorf("%s %s was logged as %v", c.method, c.target, attrs)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := httptest.NewServer(newHandler("ja", logging.New(io.Discard)))
+	defer server.Close()
+
+	r, _ := http.NewRequest("GET", server.URL+"/hello?name=Ada", nil)
+	r.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || got["greeting"] != "こんにちは、Adaさん。" {
+		t.Errorf("GET /hello == %v, %v", got, err)
+	}
+}
+
+func TestServeShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A request that is still running when shutdown starts must be finished
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, ln, handler, logging.New(io.Discard))
+	}()
+
+	answer := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			answer <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		answer <- string(body)
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned %v before the request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if got := <-answer; got != "done" {
+		t.Errorf("The request in flight got %q, want %q", got, "done")
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serve == %v, want nil", err)
+	}
+	if _, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		t.Errorf("The server still listens after shutdown")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"math/cmplx"
 	"math/rand"
 	"time"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
 )
 
 // ---------------------- Variables ----------------------------------
@@ -56,6 +58,10 @@ func split(sum int) (x, y int) {
 
 // The main entry point of the executable
 func main() {
+	// logging.Setup is a function from another package, its result is kept in a
+	// variable declared with the short := form explained above
+	logger := logging.Setup()
+	logger.Info("running the example", "topics", "variables and functions")
 	// Seed the random function since it is deterministic, meaning it will always
 	// spit out the same number given the same seed value
 	rand.Seed(int64(time.Now().Second()))
@@ -3,9 +3,12 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"math"
 	"runtime"
 	"time"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
 )
 
 // ----------------------- Conditionals -----------------------
@@ -25,12 +28,17 @@ func pow(x, n, lim float64) float64 {
 		return v
 	} else {
 		fmt.Printf("%g >= %g\n", v, lim)
+		// The print does not say which power went over, the debug log does
+		slog.Debug("pow is capped at the limit", "x", x, "n", n)
 	}
 	return lim
 }
 
 // The main entry point of the executable
 func main() {
+	// Log to standard error, run with -log-level debug to also see what pow logs
+	logger := logging.Setup()
+	logger.Info("running the example", "topics", "loops, conditionals, switch and defer")
 	// Go contains only one looping construct, the for loop which contains three components
 	// separated by semicolons
 	/*
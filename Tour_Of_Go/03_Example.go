@@ -4,6 +4,8 @@ package main
 import (
 	"fmt"
 	"math"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
 )
 
 // ------------------ Example Functions -------------------
@@ -53,6 +55,9 @@ func adder() func(int) int {
 }
 
 func main() {
+	// Setup returns a pointer, a *slog.Logger, like the pointers below
+	logger := logging.Setup()
+	logger.Info("running the example", "topics", "pointers, structs, slices, maps and closures")
 	// Go has pointers which basically  as the C++ pointers
 	// Below is a pointer that holds the memory address of a value
 	var integerPointer *int
@@ -6,6 +6,8 @@ import (
 	"math"
 	"strings"
 	"time"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
 )
 
 // ---- Defining Type methods --------
@@ -106,6 +108,9 @@ func run() error {
 //func (T) Read(b []byte) (n int, err error)
 
 func main() {
+	// Info is a method of the logger that Setup returns, methods are shown below
+	logger := logging.Setup()
+	logger.Info("running the example", "topics", "methods, interfaces, errors and readers")
 	// Vertex methods
 	v := Vertex{3, 4}
 	fmt.Println(v.Abs())
@@ -152,6 +157,7 @@ func main() {
 	// Run the error case
 	if err := run(); err != nil {
 		fmt.Println(err)
+		logger.Error("run failed", "err", err)
 	}
 
 	// Implementing the IO
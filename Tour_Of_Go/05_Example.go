@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/PenguinDan/Golang_Reference/Hello_World_Example/Package/logging"
 )
 
 // Functions
@@ -35,18 +38,23 @@ func fibonacii(n int, c chan int) {
 
 func selectFibonacci(c, quit chan int) {
 	x, y := 0, 1
+	sent, waited := 0, 0
 	for {
 		select {
 		// The case that sends a value to the c channel
 		case c <- x:
 			x, y = y, x+y
+			sent++
 		// If quit channel contains a value, this will run
 		case <-quit:
 			fmt.Println("quit")
+			// How often the select had to wait is only seen in the debug log
+			slog.Debug("fibonacci stopped", "sent", sent, "waited", waited)
 			return
 		// Default case that runs if no other case is ready
 		default:
 			fmt.Println("Default case")
+			waited++
 			time.Sleep(50 * time.Millisecond)
 		}
 
@@ -77,6 +85,10 @@ func (c *SafeCounter) Value(key string) int {
 }
 
 func main() {
+	// The logger can be used from many goroutines at once, unlike the map of
+	// SafeCounter it needs no Mutex
+	logger := logging.Setup()
+	logger.Info("running the example", "topics", "goroutines, channels and mutexes")
 	// A goroutine is a lightweight thread managed by the Go runtime
 	// The following starts a goroutine running the specified function
 	go say("world")